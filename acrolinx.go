@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"strings"
@@ -28,7 +29,8 @@ type Client struct {
 	client *http.Client
 
 	// Services for different parts of the API
-	Checking *CheckingService
+	Checking   *CheckingService
+	Dictionary *DictionaryService
 }
 
 func NewClient(signature string, urlStr string, options ...ClientOptionFunc) (*Client, error) {
@@ -58,6 +60,7 @@ func NewClient(signature string, urlStr string, options ...ClientOptionFunc) (*C
 	}
 
 	client.Checking = &CheckingService{client}
+	client.Dictionary = &DictionaryService{client}
 
 	return client, nil
 }
@@ -82,14 +85,19 @@ func (c *Client) SignIn(username string, password string) error {
 }
 
 func (c *Client) newRequest(method, path string, creds interface{}) (*http.Request, error) {
-	u := *c.platformURL
-	u.Path = c.platformURL.Path + path
-
 	jsonBody, err := json.Marshal(creds)
 	if err != nil {
 		return nil, fmt.Errorf("Error encoding JSON: %w", err)
 	}
-	body := bytes.NewReader(jsonBody)
+
+	return c.newRequestWithBody(method, path, bytes.NewReader(jsonBody))
+}
+
+// newRequestWithBody creates a request whose JSON body is read from
+// body, without buffering it in memory.
+func (c *Client) newRequestWithBody(method, path string, body io.Reader) (*http.Request, error) {
+	u := *c.platformURL
+	u.Path = c.platformURL.Path + path
 
 	req, err := http.NewRequest(method, u.String(), body)
 	if err != nil {
@@ -133,6 +141,12 @@ func (c *Client) setToken(token string) {
 	c.accessToken = token
 }
 
+// AccessToken returns the token the client authenticates with, as set
+// by WithAPIToken or obtained by SignIn.
+func (c *Client) AccessToken() string {
+	return c.accessToken
+}
+
 type Links = map[string]string
 
 type Response struct {
@@ -29,6 +29,7 @@ func TestSignIn(t *testing.T) {
 	assert.NoError(t, err)
 
 	assert.Equal(t, "VGhlIGZhbGNvbiBoZWFycyB0aGUgZmFsY29uZXIK", client.accessToken)
+	assert.Equal(t, client.accessToken, client.AccessToken())
 }
 
 func setup(t *testing.T) (*http.ServeMux, *httptest.Server, *Client) {
@@ -0,0 +1,195 @@
+package acrolinx
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// BaselineVersion is the version of the baseline file format.
+const BaselineVersion = 1
+
+// Baseline records the known issues of documents, so that later checks
+// can be limited to new issues.
+type Baseline struct {
+	Version int `json:"version"`
+	// Documents maps document paths to their known issues.
+	Documents map[string][]*BaselineEntry `json:"documents"`
+}
+
+// BaselineEntry identifies an issue by the hashes the platform reports
+// for it. The rule and surface identify issues without hashes.
+type BaselineEntry struct {
+	GoalID          string `json:"goalId"`
+	Rule            string `json:"rule"`
+	Surface         string `json:"surface,omitempty"`
+	IssueHash       string `json:"issueHash,omitempty"`
+	EnvironmentHash string `json:"environmentHash,omitempty"`
+	IndexHash       string `json:"indexHash,omitempty"`
+}
+
+type BaselineComparison struct {
+	// New lists the issues not in the baseline.
+	New []*Issue
+	// Known lists the issues in the baseline.
+	Known []*Issue
+	// Fixed lists the baseline entries no issue matched.
+	Fixed []*BaselineEntry
+}
+
+// NewBaseline records the issues of docs.
+func NewBaseline(docs []*CheckedDocument) *Baseline {
+	b := &Baseline{
+		Version:   BaselineVersion,
+		Documents: make(map[string][]*BaselineEntry),
+	}
+
+	for _, doc := range docs {
+		entries := []*BaselineEntry{}
+		for _, issue := range doc.locatedIssues() {
+			entries = append(entries, newBaselineEntry(issue.Issue))
+		}
+		b.Documents[doc.slashPath()] = entries
+	}
+
+	return b
+}
+
+func newBaselineEntry(issue *Issue) *BaselineEntry {
+	e := &BaselineEntry{
+		GoalID:  issue.GoalID,
+		Rule:    issueRuleID(issue),
+		Surface: issue.DisplaySurface,
+	}
+
+	if info := issue.PositionalInformation; info != nil && info.Hashes != nil {
+		e.IssueHash = info.Hashes.Issue
+		e.EnvironmentHash = info.Hashes.Environment
+		e.IndexHash = info.Hashes.Index
+	}
+
+	return e
+}
+
+// LoadBaseline reads a baseline written by Save.
+func LoadBaseline(r io.Reader) (*Baseline, error) {
+	var b Baseline
+	if err := json.NewDecoder(r).Decode(&b); err != nil {
+		return nil, fmt.Errorf("Error reading baseline: %w", err)
+	}
+
+	if b.Version > BaselineVersion {
+		return nil, fmt.Errorf("Error reading baseline: unsupported version %d", b.Version)
+	}
+
+	if b.Documents == nil {
+		b.Documents = make(map[string][]*BaselineEntry)
+	}
+
+	return &b, nil
+}
+
+// Save writes the baseline as JSON to w.
+func (b *Baseline) Save(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(b); err != nil {
+		return fmt.Errorf("Error writing baseline: %w", err)
+	}
+
+	return nil
+}
+
+// baselineMatchers decide whether an issue matches a baseline entry,
+// from the strictest to the most tolerant. The environment hash changes
+// when the text around an issue is edited, the index hash when issues
+// with the same surface are added or removed before it.
+var baselineMatchers = []func(e, issue *BaselineEntry) bool{
+	func(e, issue *BaselineEntry) bool {
+		return e.IssueHash != "" && e.IssueHash == issue.IssueHash &&
+			e.EnvironmentHash == issue.EnvironmentHash && e.IndexHash == issue.IndexHash
+	},
+	func(e, issue *BaselineEntry) bool {
+		return e.IssueHash != "" && e.IssueHash == issue.IssueHash && e.EnvironmentHash == issue.EnvironmentHash
+	},
+	func(e, issue *BaselineEntry) bool {
+		return e.IssueHash != "" && e.IssueHash == issue.IssueHash && e.IndexHash == issue.IndexHash
+	},
+	func(e, issue *BaselineEntry) bool {
+		return e.IssueHash != "" && e.IssueHash == issue.IssueHash
+	},
+	func(e, issue *BaselineEntry) bool {
+		return (e.IssueHash == "" || issue.IssueHash == "") &&
+			e.GoalID == issue.GoalID && e.Rule == issue.Rule && e.Surface == issue.Surface
+	},
+}
+
+// Compare matches the issues of doc with the baseline entries of its
+// path. Every entry matches at most one issue; stricter matches are
+// preferred, so that issues keep their entries when similar issues are
+// added.
+func (b *Baseline) Compare(doc *CheckedDocument) *BaselineComparison {
+	entries := b.Documents[doc.slashPath()]
+
+	var issues []*Issue
+	if doc.Result != nil {
+		issues = doc.Result.Issues
+	}
+
+	keys := make([]*BaselineEntry, len(issues))
+	for i, issue := range issues {
+		keys[i] = newBaselineEntry(issue)
+	}
+
+	usedEntries := make([]bool, len(entries))
+	known := make([]bool, len(issues))
+	for _, match := range baselineMatchers {
+		for i, key := range keys {
+			if known[i] {
+				continue
+			}
+
+			for j, e := range entries {
+				if !usedEntries[j] && match(e, key) {
+					usedEntries[j], known[i] = true, true
+					break
+				}
+			}
+		}
+	}
+
+	c := &BaselineComparison{}
+	for i, issue := range issues {
+		if known[i] {
+			c.Known = append(c.Known, issue)
+		} else {
+			c.New = append(c.New, issue)
+		}
+	}
+
+	for j, e := range entries {
+		if !usedEntries[j] {
+			c.Fixed = append(c.Fixed, e)
+		}
+	}
+
+	return c
+}
+
+// FilterNew returns copies of docs whose results only contain the
+// issues not in the baseline.
+func (b *Baseline) FilterNew(docs []*CheckedDocument) []*CheckedDocument {
+	filtered := make([]*CheckedDocument, len(docs))
+	for i, doc := range docs {
+		d := *doc
+		if doc.Result != nil {
+			result := *doc.Result
+			result.Issues = b.Compare(doc).New
+			d.Result = &result
+		}
+		filtered[i] = &d
+	}
+
+	return filtered
+}
@@ -0,0 +1,96 @@
+package acrolinx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func issueNames(issues []*Issue) []string {
+	var names []string
+	for _, issue := range issues {
+		names = append(names, issue.InternalName)
+	}
+
+	return names
+}
+
+func TestBaselineSaveLoad(t *testing.T) {
+	b := NewBaseline(reportTestDocuments())
+
+	var buf bytes.Buffer
+	assert.NoError(t, b.Save(&buf))
+	assertGolden(t, "baseline.json", buf.String())
+
+	loaded, err := LoadBaseline(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, b, loaded)
+
+	_, err = LoadBaseline(strings.NewReader(`{"version": 2}`))
+	assert.ErrorContains(t, err, "unsupported version 2")
+
+	_, err = LoadBaseline(strings.NewReader(`{`))
+	assert.ErrorContains(t, err, "Error reading baseline")
+
+	empty, err := LoadBaseline(strings.NewReader(`{"version": 1}`))
+	assert.NoError(t, err)
+	assert.NotNil(t, empty.Documents)
+}
+
+func TestBaselineCompareUnchanged(t *testing.T) {
+	docs := reportTestDocuments()
+	b := NewBaseline(docs)
+
+	c := b.Compare(docs[0])
+	assert.Empty(t, c.New)
+	assert.Len(t, c.Known, 4)
+	assert.Empty(t, c.Fixed)
+}
+
+func TestBaselineCompareChanged(t *testing.T) {
+	b := NewBaseline(reportTestDocuments())
+
+	docs := reportTestDocuments()
+	issues := docs[0].Result.Issues
+	contraction, simpler, comma := issues[0], issues[1], issues[2]
+
+	// The text around the contraction was edited.
+	contraction.PositionalInformation.Hashes.Environment = "ZW52LTMtZWRpdGVk"
+	// An earlier occurrence of the same simpler word issue was added.
+	added := *simpler
+	added.PositionalInformation = &PositionalInformation{
+		Hashes: &Hashes{Issue: simpler.PositionalInformation.Hashes.Issue, Environment: "ZW52LW5ldw==", Index: "aW5kZXgtbmV3"},
+	}
+	// The comma issue was fixed and a new issue appeared.
+	docs[0].Result.Issues = []*Issue{contraction, &added, simpler, issues[3], {
+		GoalID:                "CLARITY",
+		InternalName:          "passive_voice",
+		PositionalInformation: &PositionalInformation{Hashes: &Hashes{Issue: "aXNzdWUtNA=="}},
+	}}
+
+	c := b.Compare(docs[0])
+	assert.Equal(t, []string{"simpler_word", "passive_voice"}, issueNames(c.New))
+	assert.Same(t, &added, c.New[0])
+	assert.Equal(t, []string{"contraction", "simpler_word", "document_too_long"}, issueNames(c.Known))
+	assert.Len(t, c.Fixed, 1)
+	assert.Equal(t, comma.InternalName, c.Fixed[0].Rule)
+}
+
+func TestBaselineFilterNew(t *testing.T) {
+	docs := reportTestDocuments()
+	b := NewBaseline(docs[:1])
+
+	docs[1].Result.Issues = []*Issue{{GoalID: "CLARITY", InternalName: "spelling", DisplaySurface: "teh"}}
+	filtered := b.FilterNew(docs)
+
+	assert.Empty(t, filtered[0].Result.Issues)
+	assert.Equal(t, 74, filtered[0].Result.Quality.Score)
+	assert.Len(t, docs[0].Result.Issues, 4)
+	assert.Equal(t, []string{"spelling"}, issueNames(filtered[1].Result.Issues))
+
+	// Issues without hashes match by rule and surface.
+	b = NewBaseline(docs[1:])
+	assert.Empty(t, b.FilterNew(docs[1:])[0].Result.Issues)
+}
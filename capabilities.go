@@ -0,0 +1,181 @@
+package acrolinx
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Fingerprint returns a stable hash of the capabilities. It changes
+// whenever the platform configuration relevant to checking changes.
+func (c *Capabilities) Fingerprint() string {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *Capabilities) GuidanceProfileByID(id string) *GuidanceProfile {
+	for _, p := range c.GuidanceProfiles {
+		if p.ID == id {
+			return p
+		}
+	}
+
+	return nil
+}
+
+func (c *Capabilities) GuidanceProfileByDisplayName(name string) *GuidanceProfile {
+	for _, p := range c.GuidanceProfiles {
+		if p.DisplayName == name {
+			return p
+		}
+	}
+
+	return nil
+}
+
+// GuidanceProfileByLanguage returns the default guidance profile if it
+// matches the language, or else the first profile for the language.
+func (c *Capabilities) GuidanceProfileByLanguage(languageID string) *GuidanceProfile {
+	if p := c.GuidanceProfileByID(c.DefaultGuidanceProfileID); p != nil && p.Language != nil && p.Language.ID == languageID {
+		return p
+	}
+
+	for _, p := range c.GuidanceProfiles {
+		if p.Language != nil && p.Language.ID == languageID {
+			return p
+		}
+	}
+
+	return nil
+}
+
+// ProfileGoals returns the goals of the guidance profile with the
+// given ID, or nil if there is no such profile.
+func (c *Capabilities) ProfileGoals(profileID string) []*Goal {
+	p := c.GuidanceProfileByID(profileID)
+	if p == nil {
+		return nil
+	}
+
+	return p.Goals
+}
+
+func (c *Capabilities) SupportsContentFormat(id string) bool {
+	for _, f := range c.ContentFormats {
+		if f.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *Capabilities) SupportsContentEncoding(encoding string) bool {
+	return slices.Contains(c.ContentEncodings, encoding)
+}
+
+func (c *Capabilities) SupportsCheckType(checkType string) bool {
+	return slices.Contains(c.CheckTypes, checkType)
+}
+
+func (c *Capabilities) SupportsReportType(reportType string) bool {
+	return slices.Contains(c.ReportTypes, reportType)
+}
+
+// CapabilitiesCache fetches capabilities once per locale and keeps
+// them until they are older than the TTL. Concurrent misses for the
+// same locale share one request.
+type CapabilitiesCache struct {
+	service *CheckingService
+	ttl     time.Duration
+	now     func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*cachedCapabilities
+	fetches map[string]*capabilitiesFetch
+}
+
+type cachedCapabilities struct {
+	caps      *Capabilities
+	fetchedAt time.Time
+}
+
+// capabilitiesFetch is a request in progress, whose result is shared
+// by everyone asking for the same locale until it completes.
+type capabilitiesFetch struct {
+	done chan struct{}
+	caps *Capabilities
+	err  error
+	// waiters counts the callers waiting for the request of another.
+	waiters int
+}
+
+// NewCapabilitiesCache creates a cache on top of service. A zero TTL
+// keeps capabilities until they are refreshed explicitly.
+func NewCapabilitiesCache(service *CheckingService, ttl time.Duration) *CapabilitiesCache {
+	return &CapabilitiesCache{
+		service: service,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]*cachedCapabilities),
+		fetches: make(map[string]*capabilitiesFetch),
+	}
+}
+
+func (c *CapabilitiesCache) Get(locale string) (*Capabilities, error) {
+	c.mu.Lock()
+	entry := c.entries[locale]
+	c.mu.Unlock()
+
+	if entry != nil && (c.ttl <= 0 || c.now().Sub(entry.fetchedAt) <= c.ttl) {
+		return entry.caps, nil
+	}
+
+	return c.Refresh(locale)
+}
+
+// Refresh fetches the capabilities for locale from the platform,
+// regardless of what is cached. It waits for a fetch of the locale
+// already in progress instead of starting another one.
+func (c *CapabilitiesCache) Refresh(locale string) (*Capabilities, error) {
+	c.mu.Lock()
+	if f := c.fetches[locale]; f != nil {
+		f.waiters++
+		c.mu.Unlock()
+		<-f.done
+		return f.caps, f.err
+	}
+
+	f := &capabilitiesFetch{done: make(chan struct{})}
+	c.fetches[locale] = f
+	c.mu.Unlock()
+
+	f.caps, _, f.err = c.service.GetCapabilities(&GetCapabilitiesOptions{Locale: locale})
+
+	c.mu.Lock()
+	delete(c.fetches, locale)
+	if f.err == nil {
+		c.entries[locale] = &cachedCapabilities{caps: f.caps, fetchedAt: c.now()}
+	} else {
+		f.caps = nil
+	}
+	c.mu.Unlock()
+	close(f.done)
+
+	return f.caps, f.err
+}
+
+func (c *CapabilitiesCache) Invalidate(locale string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, locale)
+}
@@ -0,0 +1,191 @@
+package acrolinx
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCapabilitiesFingerprint(t *testing.T) {
+	caps := &Capabilities{
+		DefaultGuidanceProfileID: "en",
+		CheckTypes:               []string{"batch"},
+	}
+	same := &Capabilities{
+		DefaultGuidanceProfileID: "en",
+		CheckTypes:               []string{"batch"},
+	}
+	other := &Capabilities{
+		DefaultGuidanceProfileID: "de",
+		CheckTypes:               []string{"batch"},
+	}
+
+	assert.Len(t, caps.Fingerprint(), 64)
+	assert.Equal(t, caps.Fingerprint(), same.Fingerprint())
+	assert.NotEqual(t, caps.Fingerprint(), other.Fingerprint())
+}
+
+func testCapabilities() *Capabilities {
+	return &Capabilities{
+		DefaultGuidanceProfileID: "en-2",
+		GuidanceProfiles: []*GuidanceProfile{
+			{
+				ID:          "en-1",
+				DisplayName: "en - Marketing",
+				Language:    &Language{"en", "English"},
+				Goals: []*Goal{
+					{ID: "CLARITY", DisplayName: "Clarity", Scoring: "required"},
+				},
+			},
+			{
+				ID:          "en-2",
+				DisplayName: "en - Technical",
+				Language:    &Language{"en", "English"},
+				Goals: []*Goal{
+					{ID: "CLARITY", DisplayName: "Clarity", Scoring: "required"},
+					{ID: "TONE", DisplayName: "Tone", Scoring: "recommended"},
+				},
+			},
+			{
+				ID:          "de-1",
+				DisplayName: "de - Blog Posts",
+				Language:    &Language{"de", "German"},
+			},
+		},
+		ContentFormats: []*ContentFormat{
+			{"TEXT", "Plain Text"},
+			{"MARKDOWN", "Markdown"},
+			{"XML", "XML"},
+			{"MS_OFFICE", "Microsoft Office Container"},
+		},
+		ContentEncodings: []string{"none", "base64"},
+		CheckTypes:       []string{"interactive", "batch", "baseline", "automated"},
+		ReferencePattern: "\\.(xml|XML|xhtm|XHTM|xhtml|XHTML)$|\\.(svg|SVG|resx|RESX)$",
+		ReportTypes:      []string{"scorecard"},
+	}
+}
+
+func TestCapabilitiesLookup(t *testing.T) {
+	caps := testCapabilities()
+
+	assert.Equal(t, "de - Blog Posts", caps.GuidanceProfileByID("de-1").DisplayName)
+	assert.Nil(t, caps.GuidanceProfileByID("fr-1"))
+
+	assert.Equal(t, "en-1", caps.GuidanceProfileByDisplayName("en - Marketing").ID)
+	assert.Nil(t, caps.GuidanceProfileByDisplayName("fr - Marketing"))
+
+	assert.Equal(t, "en-2", caps.GuidanceProfileByLanguage("en").ID)
+	assert.Equal(t, "de-1", caps.GuidanceProfileByLanguage("de").ID)
+	assert.Nil(t, caps.GuidanceProfileByLanguage("fr"))
+
+	goals := caps.ProfileGoals("en-2")
+	assert.Len(t, goals, 2)
+	assert.Equal(t, "TONE", goals[1].ID)
+	assert.Nil(t, caps.ProfileGoals("fr-1"))
+}
+
+func TestCapabilitiesSupports(t *testing.T) {
+	caps := testCapabilities()
+
+	assert.True(t, caps.SupportsContentFormat("MARKDOWN"))
+	assert.False(t, caps.SupportsContentFormat("DITA"))
+	assert.True(t, caps.SupportsContentEncoding("base64"))
+	assert.False(t, caps.SupportsContentEncoding("gzip"))
+	assert.True(t, caps.SupportsCheckType("batch"))
+	assert.False(t, caps.SupportsCheckType("quick"))
+	assert.True(t, caps.SupportsReportType("scorecard"))
+	assert.False(t, caps.SupportsReportType("termHarvesting"))
+}
+
+func TestCapabilitiesCache(t *testing.T) {
+	mux, server, client := setup(t)
+	defer teardown(server)
+
+	requests := make(map[string]int)
+	mux.HandleFunc("/api/v1/checking/capabilities", func(w http.ResponseWriter, r *http.Request) {
+		requests[r.Header.Get(headerLocale)]++
+		mustWriteHTTPResponse(t, w, "get_capabilities.json")
+	})
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := NewCapabilitiesCache(client.Checking, time.Hour)
+	cache.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		caps, err := cache.Get("en")
+		assert.NoError(t, err)
+		assert.True(t, caps.SupportsContentFormat("XML"))
+	}
+	_, err := cache.Get("de")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"en": 1, "de": 1}, requests)
+
+	now = now.Add(2 * time.Hour)
+	_, err = cache.Get("en")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, requests["en"])
+
+	_, err = cache.Refresh("en")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, requests["en"])
+
+	cache.Invalidate("en")
+	_, err = cache.Get("en")
+	assert.NoError(t, err)
+	assert.Equal(t, 4, requests["en"])
+}
+
+func TestCapabilitiesCacheConcurrentMisses(t *testing.T) {
+	mux, server, client := setup(t)
+	defer teardown(server)
+
+	var requests atomic.Int32
+	release := make(chan struct{})
+	mux.HandleFunc("/api/v1/checking/capabilities", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+		mustWriteHTTPResponse(t, w, "get_capabilities.json")
+	})
+
+	cache := NewCapabilitiesCache(client.Checking, time.Hour)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			caps, err := cache.Get("en")
+			assert.NoError(t, err)
+			assert.NotNil(t, caps)
+		}()
+	}
+
+	// Release the request once the other callers wait for it.
+	assert.Eventually(t, func() bool {
+		cache.mu.Lock()
+		defer cache.mu.Unlock()
+		f := cache.fetches["en"]
+		return f != nil && f.waiters == 4
+	}, 5*time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestCapabilitiesCacheWithError(t *testing.T) {
+	mux, server, client := setup(t)
+	defer teardown(server)
+
+	mux.HandleFunc("/api/v1/checking/capabilities", func(w http.ResponseWriter, r *http.Request) {
+		mustWriteHTTPResponse(t, w, "error.json")
+	})
+
+	cache := NewCapabilitiesCache(client.Checking, 0)
+	_, err := cache.Get("en")
+	assert.EqualError(t, err, "Please provide a valid signature in the X-Acrolinx-Client header.")
+}
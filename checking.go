@@ -1,10 +1,21 @@
 package acrolinx
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"time"
 )
 
+// defaultPollInterval is used when the platform does not suggest a
+// retry interval while a check is still in progress.
+var defaultPollInterval = time.Second
+
+// Checker submits a check and waits for its final result.
+type Checker interface {
+	Check(ctx context.Context, opts *SubmitCheckOptions) (*CheckResult, error)
+}
+
 type CheckingService struct {
 	client *Client
 }
@@ -115,3 +126,44 @@ func (s *CheckingService) CancelCheck(check *Check) (*CancelledCheck, Links, err
 
 	return &result, nil, nil
 }
+
+// WaitForCheckResult polls the result of check until the platform
+// reports it as finished or ctx is done.
+func (s *CheckingService) WaitForCheckResult(ctx context.Context, check *Check) (*CheckResult, Links, error) {
+	for {
+		result, links, err := s.GetCheckResult(check)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if result.Progress == nil {
+			return result, links, nil
+		}
+
+		wait := time.Duration(result.Progress.RetryAfter) * time.Second
+		if wait <= 0 {
+			wait = defaultPollInterval
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, nil, fmt.Errorf("Error waiting for check %s: %w", check.ID, ctx.Err())
+		case <-time.After(wait):
+		}
+	}
+}
+
+// Check submits a check and waits for its result.
+func (s *CheckingService) Check(ctx context.Context, opts *SubmitCheckOptions) (*CheckResult, error) {
+	check, _, err := s.SubmitCheck(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result, _, err := s.WaitForCheckResult(ctx, check)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -1,8 +1,11 @@
 package acrolinx
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -317,3 +320,52 @@ func TestCancelCheck(t *testing.T) {
 
 	assert.Equal(t, expectedResult, result)
 }
+
+func TestCheck(t *testing.T) {
+	mux, server, client := setup(t)
+	defer teardown(server)
+
+	defer func(d time.Duration) { defaultPollInterval = d }(defaultPollInterval)
+	defaultPollInterval = time.Millisecond
+
+	mux.HandleFunc("/api/v1/checking/checks", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodPost)
+		mustWriteHTTPResponse(t, w, "submit_check.json")
+	})
+
+	polls := 0
+	mux.HandleFunc("/api/v1/checking/checks/052929ee-be0c-46a7-87ce-eebd308fef6e",
+		func(w http.ResponseWriter, r *http.Request) {
+			testMethod(t, r, http.MethodGet)
+			polls++
+			if polls < 3 {
+				fmt.Fprint(w, `{"progress":{"percent":50,"message":"Still processing","retryAfter":0}}`)
+				return
+			}
+			mustWriteHTTPResponse(t, w, "check_result.json")
+		})
+
+	result, err := client.Checking.Check(context.Background(), &SubmitCheckOptions{Content: "In most cases"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 3, polls)
+	assert.Nil(t, result.Progress)
+	assert.Equal(t, "052929ee-be0c-46a7-87ce-eebd308fef6e", result.ID)
+}
+
+func TestWaitForCheckResultCancelled(t *testing.T) {
+	mux, server, client := setup(t)
+	defer teardown(server)
+
+	mux.HandleFunc("/api/v1/checking/checks/052929ee-be0c-46a7-87ce-eebd308fef6e",
+		func(w http.ResponseWriter, r *http.Request) {
+			mustWriteHTTPResponse(t, w, "progress.json")
+		})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	check := &Check{"052929ee-be0c-46a7-87ce-eebd308fef6e"}
+	_, _, err := client.Checking.WaitForCheckResult(ctx, check)
+	assert.ErrorIs(t, err, context.Canceled)
+}
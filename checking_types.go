@@ -132,6 +132,10 @@ type Issue struct {
 	Debug                 *Debug                 `json:"debug"`
 	CanAddToDictionary    bool                   `json:"canAddToDictionary"`
 	Links                 Links                  `json:"links"`
+
+	// Range is the location of the issue in the checked content. It is
+	// only set after calling PositionIndex.Annotate.
+	Range *Range `json:"-"`
 }
 
 type Keyword struct {
@@ -217,10 +221,11 @@ type Score struct {
 }
 
 type SubmitCheckOptions struct {
-	Content      string        `json:"content"`
-	CheckOptions *CheckOptions `json:"checkOptions"`
-	Document     *Document     `json:"document"`
-	Language     string        `json:"language"`
+	Content         string        `json:"content"`
+	ContentEncoding string        `json:"contentEncoding,omitempty"`
+	CheckOptions    *CheckOptions `json:"checkOptions"`
+	Document        *Document     `json:"document"`
+	Language        string        `json:"language"`
 }
 
 type Suggestion struct {
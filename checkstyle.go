@@ -0,0 +1,59 @@
+package acrolinx
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+type CheckstyleReport struct {
+	XMLName xml.Name          `xml:"checkstyle"`
+	Version string            `xml:"version,attr"`
+	Files   []*CheckstyleFile `xml:"file"`
+}
+
+type CheckstyleFile struct {
+	Name   string             `xml:"name,attr"`
+	Errors []*CheckstyleError `xml:"error"`
+}
+
+type CheckstyleError struct {
+	Line     int    `xml:"line,attr,omitempty"`
+	Column   int    `xml:"column,attr,omitempty"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// NewCheckstyleReport lists the issues of every document as
+// Checkstyle errors. Issues without matches have no line and column.
+func NewCheckstyleReport(docs []*CheckedDocument) *CheckstyleReport {
+	report := &CheckstyleReport{Version: "8.0"}
+
+	for _, doc := range sortedDocuments(docs) {
+		file := &CheckstyleFile{Name: doc.slashPath()}
+
+		for _, issue := range doc.locatedIssues() {
+			e := &CheckstyleError{
+				Severity: doc.severity(issue.Issue),
+				Message:  issueTitle(issue.Issue),
+				Source:   "acrolinx." + issue.GoalID + "." + issueRuleID(issue.Issue),
+			}
+
+			if issue.Located {
+				e.Line = issue.Range.Start.Line
+				e.Column = issue.Range.Start.Column
+			}
+
+			file.Errors = append(file.Errors, e)
+		}
+
+		report.Files = append(report.Files, file)
+	}
+
+	return report
+}
+
+// WriteCheckstyle writes the Checkstyle XML report for docs to w.
+func WriteCheckstyle(w io.Writer, docs []*CheckedDocument) error {
+	return writeXML(w, NewCheckstyleReport(docs))
+}
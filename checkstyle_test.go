@@ -0,0 +1,37 @@
+package acrolinx
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteCheckstyle(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, WriteCheckstyle(&buf, reportTestDocuments()))
+
+	assertGolden(t, "report.checkstyle.xml", buf.String())
+}
+
+func TestNewCheckstyleReport(t *testing.T) {
+	report := NewCheckstyleReport(reportTestDocuments())
+
+	assert.Len(t, report.Files, 2)
+	assert.Empty(t, report.Files[0].Errors)
+
+	errors := report.Files[1].Errors
+	assert.Len(t, errors, 4)
+	assert.Equal(t, &CheckstyleError{
+		Severity: "error",
+		Message:  "This document is long.",
+		Source:   "acrolinx.CLARITY.document_too_long",
+	}, errors[0])
+	assert.Equal(t, &CheckstyleError{
+		Line:     4,
+		Column:   7,
+		Severity: "warning",
+		Message:  "Use the contraction isn’t.",
+		Source:   "acrolinx.CONSISTENCY.contraction",
+	}, errors[3])
+}
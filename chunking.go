@@ -0,0 +1,473 @@
+package acrolinx
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	defaultChunkSize        = 100000
+	defaultChunkConcurrency = 4
+)
+
+// qualityStatusRanks orders quality statuses from best to worst.
+var qualityStatusRanks = map[string]int{
+	"green":  1,
+	"yellow": 2,
+	"red":    3,
+}
+
+// Chunk is a part of a document that is checked on its own.
+type Chunk struct {
+	Content string
+
+	// ByteOffset is the position of the chunk in the document in bytes.
+	ByteOffset int
+
+	// Offset is the position of the chunk in the document in UTF-16
+	// code units, the unit of all Match offsets.
+	Offset int
+}
+
+type ChunkOptions struct {
+	// MaxSize is the maximum size of a chunk in bytes. Defaults to
+	// 100000.
+	MaxSize int
+
+	// Concurrency is the maximum number of chunks checked at the same
+	// time. Defaults to 4.
+	Concurrency int
+}
+
+// SplitContent splits content into chunks of at most maxSize bytes.
+// Chunks end at paragraph boundaries if possible, or else at sentence
+// boundaries, whitespace or, as a last resort, anywhere between two
+// characters. Joining the chunks yields content again.
+func SplitContent(content string, maxSize int) []*Chunk {
+	if maxSize <= 0 {
+		maxSize = defaultChunkSize
+	}
+
+	var chunks []*Chunk
+	byteOffset, offset := 0, 0
+	for len(content) > 0 {
+		n := len(content)
+		if n > maxSize {
+			n = chunkBoundary(content, maxSize)
+		}
+
+		chunk := &Chunk{Content: content[:n], ByteOffset: byteOffset, Offset: offset}
+		chunks = append(chunks, chunk)
+
+		content = content[n:]
+		byteOffset += n
+		offset += utf16Length(chunk.Content)
+	}
+
+	return chunks
+}
+
+// chunkBoundary returns the length of the longest prefix of content
+// of at most maxSize bytes that ends at the best kind of boundary.
+func chunkBoundary(content string, maxSize int) int {
+	window := content[:maxSize]
+
+	if i := strings.LastIndex(window, "\n\n"); i > 0 {
+		return i + 2
+	}
+
+	if i := lastSentenceEnd(window); i > 0 {
+		return i
+	}
+
+	if i := strings.LastIndexFunc(window, unicode.IsSpace); i > 0 {
+		_, size := utf8.DecodeRuneInString(window[i:])
+		return i + size
+	}
+
+	n := maxSize
+	for n > 0 && !utf8.RuneStart(content[n]) {
+		n--
+	}
+
+	if n == 0 {
+		_, n = utf8.DecodeRuneInString(content)
+	}
+
+	return n
+}
+
+// lastSentenceEnd returns the position after the whitespace that
+// follows the last sentence-ending punctuation in s, or -1.
+func lastSentenceEnd(s string) int {
+	for i := len(s) - 1; i > 0; i-- {
+		if s[i] != ' ' && s[i] != '\n' {
+			continue
+		}
+
+		switch s[i-1] {
+		case '.', '!', '?':
+			return i + 1
+		}
+	}
+
+	return -1
+}
+
+// CheckChunked checks the content of opts in chunks of at most
+// ChunkOptions.MaxSize bytes and merges the results. Content that fits
+// into a single chunk is checked as usual.
+func CheckChunked(ctx context.Context, checker Checker, opts *SubmitCheckOptions, chunkOpts *ChunkOptions) (*CheckResult, error) {
+	maxSize, concurrency := defaultChunkSize, defaultChunkConcurrency
+	if chunkOpts != nil && chunkOpts.MaxSize > 0 {
+		maxSize = chunkOpts.MaxSize
+	}
+	if chunkOpts != nil && chunkOpts.Concurrency > 0 {
+		concurrency = chunkOpts.Concurrency
+	}
+
+	if len(opts.Content) <= maxSize {
+		return checker.Check(ctx, opts)
+	}
+
+	if opts.ContentEncoding != "" && opts.ContentEncoding != ContentEncodingNone {
+		return nil, errors.New("Error checking in chunks: encoded content can't be split")
+	}
+
+	chunks := SplitContent(opts.Content, maxSize)
+	results := make([]*CheckResult, len(chunks))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var firstErr error
+	sem := make(chan struct{}, concurrency)
+
+	for i, chunk := range chunks {
+		submitOpts, ok := chunkSubmitOptions(opts, chunk)
+		if !ok {
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+
+			result, err := checker.Check(ctx, submitOpts)
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			results[i] = result
+		}(i)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return MergeCheckResults(chunks, results), nil
+}
+
+// chunkSubmitOptions returns the options to check chunk with. Partial
+// check ranges are clipped to the chunk; if none of them overlap it,
+// the chunk needn't be checked at all.
+func chunkSubmitOptions(opts *SubmitCheckOptions, chunk *Chunk) (*SubmitCheckOptions, bool) {
+	chunkOpts := *opts
+	chunkOpts.Content = chunk.Content
+
+	if opts.CheckOptions == nil || len(opts.CheckOptions.PartialCheckRanges) == 0 {
+		return &chunkOpts, true
+	}
+
+	begin, end := chunk.Offset, chunk.Offset+utf16Length(chunk.Content)
+	checkOpts := *opts.CheckOptions
+	checkOpts.PartialCheckRanges = nil
+	for _, r := range opts.CheckOptions.PartialCheckRanges {
+		if r.End <= begin || r.Begin >= end {
+			continue
+		}
+
+		checkOpts.PartialCheckRanges = append(checkOpts.PartialCheckRanges, &PartialCheckRange{
+			Begin: max(r.Begin, begin) - begin,
+			End:   min(r.End, end) - begin,
+		})
+	}
+
+	if len(checkOpts.PartialCheckRanges) == 0 {
+		return nil, false
+	}
+
+	chunkOpts.CheckOptions = &checkOpts
+	return &chunkOpts, true
+}
+
+// MergeCheckResults combines the results of checking chunks into a
+// result for the whole document. results[i] is the result of
+// chunks[i] and may be nil if the chunk wasn't checked. The original
+// offsets of all matches are remapped to the document; extracted
+// offsets stay relative to their chunk. Scores are averaged weighted by
+// the number of words in each chunk, except for the "minimum" strategy
+// score and the status, which take the worst value of all chunks.
+func MergeCheckResults(chunks []*Chunk, results []*CheckResult) *CheckResult {
+	merged := &CheckResult{}
+	var weights []int
+	var checked []*CheckResult
+	goals := make(map[string]*Goal)
+
+	for i, result := range results {
+		if result == nil {
+			continue
+		}
+
+		if len(checked) == 0 {
+			merged.ID = result.ID
+			merged.CheckOptions = result.CheckOptions
+			merged.Document = result.Document
+			merged.Embed = result.Embed
+			merged.Reports = result.Reports
+			merged.RuntimeStatistics = result.RuntimeStatistics
+			merged.DictionaryScopes = result.DictionaryScopes
+		}
+		checked = append(checked, result)
+
+		offset := chunks[i].Offset
+		for _, issue := range result.Issues {
+			merged.Issues = append(merged.Issues, shiftIssue(issue, offset))
+		}
+
+		words := 0
+		if result.Counts != nil {
+			if merged.Counts == nil {
+				merged.Counts = &Counts{}
+			}
+			merged.Counts.Sentences += result.Counts.Sentences
+			merged.Counts.Words += result.Counts.Words
+			merged.Counts.Issues += result.Counts.Issues
+			merged.Counts.ScoredIssues += result.Counts.ScoredIssues
+			words = result.Counts.Words
+		}
+		weights = append(weights, words)
+
+		for _, goal := range result.Goals {
+			if g, ok := goals[goal.ID]; ok {
+				g.Issues += goal.Issues
+				continue
+			}
+			g := *goal
+			goals[goal.ID] = &g
+			merged.Goals = append(merged.Goals, &g)
+		}
+
+		merged.Keywords = mergeKeywords(merged.Keywords, result.Keywords, offset)
+	}
+
+	merged.Quality = mergeQuality(checked, weights)
+	return merged
+}
+
+func shiftIssue(issue *Issue, offset int) *Issue {
+	shifted := *issue
+
+	if issue.PositionalInformation != nil {
+		shifted.PositionalInformation = shiftPositionalInformation(issue.PositionalInformation, offset)
+	}
+
+	if issue.SubIssues != nil {
+		shifted.SubIssues = make([]*Issue, len(issue.SubIssues))
+		for i, sub := range issue.SubIssues {
+			shifted.SubIssues[i] = shiftIssue(sub, offset)
+		}
+	}
+
+	return &shifted
+}
+
+func shiftPositionalInformation(info *PositionalInformation, offset int) *PositionalInformation {
+	shifted := &PositionalInformation{Hashes: info.Hashes}
+	if info.Matches != nil {
+		shifted.Matches = make([]*Match, len(info.Matches))
+		for i, m := range info.Matches {
+			match := *m
+			match.OriginalBegin += offset
+			match.OriginalEnd += offset
+			shifted.Matches[i] = &match
+		}
+	}
+
+	return shifted
+}
+
+// mergeKeywords adds the discovered keywords of a chunk to merged,
+// summing up their counts and collecting their occurrences.
+func mergeKeywords(merged *Keywords, keywords *Keywords, offset int) *Keywords {
+	if keywords == nil {
+		return merged
+	}
+
+	if merged == nil {
+		merged = &Keywords{
+			Proposed: keywords.Proposed,
+			Target:   keywords.Target,
+			Links:    keywords.Links,
+		}
+	}
+
+	for _, keyword := range keywords.Discovered {
+		var existing *Keyword
+		for _, k := range merged.Discovered {
+			if k.Keyword == keyword.Keyword {
+				existing = k
+				break
+			}
+		}
+
+		if existing == nil {
+			k := *keyword
+			k.Occurrences = nil
+			existing = &k
+			merged.Discovered = append(merged.Discovered, existing)
+		} else {
+			existing.Count += keyword.Count
+			existing.Density = math.Max(existing.Density, keyword.Density)
+			existing.Prominence = math.Max(existing.Prominence, keyword.Prominence)
+		}
+
+		for _, occurrence := range keyword.Occurrences {
+			existing.Occurrences = append(existing.Occurrences, shiftPositionalInformation(occurrence, offset))
+		}
+	}
+
+	return merged
+}
+
+func mergeQuality(results []*CheckResult, weights []int) *Quality {
+	var quality *Quality
+	var scores []int
+	var scoreWeights []int
+	strategies := newScoreMerger()
+	goals := newScoreMerger()
+	metrics := newScoreMerger()
+
+	for i, result := range results {
+		q := result.Quality
+		if q == nil {
+			continue
+		}
+
+		weight := weights[i]
+		if quality == nil {
+			quality = &Quality{Status: q.Status}
+		} else if qualityStatusRanks[q.Status] > qualityStatusRanks[quality.Status] {
+			quality.Status = q.Status
+		}
+
+		scores = append(scores, q.Score)
+		scoreWeights = append(scoreWeights, weight)
+		strategies.add(q.ScoresByStrategy, weight)
+		goals.add(q.ScoresByGoal, weight)
+		metrics.add(q.Metrics, weight)
+	}
+
+	if quality == nil {
+		return nil
+	}
+
+	quality.Score = weightedAverage(scores, scoreWeights)
+	quality.ScoresByStrategy = strategies.merge()
+	quality.ScoresByGoal = goals.merge()
+	quality.Metrics = metrics.merge()
+
+	for _, s := range quality.ScoresByStrategy {
+		if s.ID == "minimum" {
+			s.Score = strategies.min[s.ID]
+		}
+	}
+
+	return quality
+}
+
+// scoreMerger collects scores by ID over several chunks.
+type scoreMerger struct {
+	ids     []string
+	scores  map[string][]int
+	weights map[string][]int
+	min     map[string]int
+}
+
+func newScoreMerger() *scoreMerger {
+	return &scoreMerger{
+		scores:  make(map[string][]int),
+		weights: make(map[string][]int),
+		min:     make(map[string]int),
+	}
+}
+
+func (m *scoreMerger) add(scores []*Score, weight int) {
+	for _, s := range scores {
+		if _, ok := m.scores[s.ID]; !ok {
+			m.ids = append(m.ids, s.ID)
+			m.min[s.ID] = s.Score
+		}
+		m.scores[s.ID] = append(m.scores[s.ID], s.Score)
+		m.weights[s.ID] = append(m.weights[s.ID], weight)
+		m.min[s.ID] = min(m.min[s.ID], s.Score)
+	}
+}
+
+func (m *scoreMerger) merge() []*Score {
+	if len(m.ids) == 0 {
+		return nil
+	}
+
+	merged := make([]*Score, len(m.ids))
+	for i, id := range m.ids {
+		merged[i] = &Score{ID: id, Score: weightedAverage(m.scores[id], m.weights[id])}
+	}
+
+	return merged
+}
+
+// weightedAverage returns the rounded weighted average of values. If
+// all weights are zero, all values count the same.
+func weightedAverage(values []int, weights []int) int {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sum, total := 0, 0
+	for i, v := range values {
+		sum += v * weights[i]
+		total += weights[i]
+	}
+
+	if total == 0 {
+		for _, v := range values {
+			sum += v
+		}
+		total = len(values)
+	}
+
+	return int(math.Round(float64(sum) / float64(total)))
+}
@@ -0,0 +1,201 @@
+package acrolinx
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func joinChunks(chunks []*Chunk) string {
+	var b strings.Builder
+	for _, c := range chunks {
+		b.WriteString(c.Content)
+	}
+	return b.String()
+}
+
+func TestSplitContent(t *testing.T) {
+	content := "First paragraph. Still first.\n\nSecond paragraph is here. It has two sentences.\n\nThird."
+
+	chunks := SplitContent(content, 40)
+	assert.Equal(t, content, joinChunks(chunks))
+	assert.Equal(t, []*Chunk{
+		{"First paragraph. Still first.\n\n", 0, 0},
+		{"Second paragraph is here. ", 31, 31},
+		{"It has two sentences.\n\nThird.", 57, 57},
+	}, chunks)
+}
+
+func TestSplitContentAtWhitespace(t *testing.T) {
+	chunks := SplitContent("one two three four", 10)
+	assert.Equal(t, []string{"one two ", "three four"}, chunkContents(chunks))
+}
+
+func TestSplitContentMultibyte(t *testing.T) {
+	content := "日本語のテキスト😀😀"
+
+	chunks := SplitContent(content, 7)
+	assert.Equal(t, content, joinChunks(chunks))
+	for _, c := range chunks {
+		assert.LessOrEqual(t, len(c.Content), 7)
+		assert.True(t, strings.ToValidUTF8(c.Content, "") == c.Content)
+	}
+
+	last := chunks[len(chunks)-1]
+	assert.Equal(t, "😀", last.Content)
+	assert.Equal(t, utf16Length(content)-2, last.Offset)
+	assert.Equal(t, len(content)-4, last.ByteOffset)
+}
+
+func TestSplitContentTinyMaxSize(t *testing.T) {
+	chunks := SplitContent("😀a", 1)
+	assert.Equal(t, []string{"😀", "a"}, chunkContents(chunks))
+}
+
+func chunkContents(chunks []*Chunk) []string {
+	contents := make([]string, len(chunks))
+	for i, c := range chunks {
+		contents[i] = c.Content
+	}
+	return contents
+}
+
+type chunkChecker struct {
+	mu       sync.Mutex
+	contents []string
+	err      error
+}
+
+// Check reports an issue on the first word of every chunk.
+func (c *chunkChecker) Check(ctx context.Context, opts *SubmitCheckOptions) (*CheckResult, error) {
+	c.mu.Lock()
+	c.contents = append(c.contents, opts.Content)
+	c.mu.Unlock()
+
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	word := strings.Fields(opts.Content)[0]
+	words := len(strings.Fields(opts.Content))
+	score := 100 - 10*words
+
+	return &CheckResult{
+		ID:     "check",
+		Counts: &Counts{Sentences: 1, Words: words, Issues: 1, ScoredIssues: 1},
+		Goals:  []*Goal{{ID: "CLARITY", Issues: 1}},
+		Quality: &Quality{
+			Score:            score,
+			Status:           map[bool]string{true: "red", false: "green"}[strings.HasPrefix(word, "Bad")],
+			ScoresByStrategy: []*Score{{"average", score}, {"minimum", score}},
+			ScoresByGoal:     []*Score{{"CLARITY", score}},
+		},
+		Issues: []*Issue{
+			{
+				GoalID: "CLARITY",
+				PositionalInformation: &PositionalInformation{
+					Matches: []*Match{{word, 0, len(word), word, 0, len(word)}},
+				},
+				SubIssues: []*Issue{
+					{PositionalInformation: &PositionalInformation{Matches: []*Match{{word, 0, 1, word, 0, 1}}}},
+				},
+			},
+		},
+	}, nil
+}
+
+func TestCheckChunked(t *testing.T) {
+	content := "Good sentence one.\n\nBad sentence number two is longer."
+	checker := &chunkChecker{}
+
+	result, err := CheckChunked(context.Background(), checker, &SubmitCheckOptions{Content: content},
+		&ChunkOptions{MaxSize: 40})
+	assert.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{"Good sentence one.\n\n", "Bad sentence number two is longer."}, checker.contents)
+
+	assert.Len(t, result.Issues, 2)
+	second := result.Issues[1].PositionalInformation.Matches[0]
+	assert.Equal(t, 20, second.OriginalBegin)
+	assert.Equal(t, 23, second.OriginalEnd)
+	assert.Equal(t, "Bad", content[second.OriginalBegin:second.OriginalEnd])
+	assert.Equal(t, 20, result.Issues[1].SubIssues[0].PositionalInformation.Matches[0].OriginalBegin)
+	assert.Equal(t, 0, second.ExtractedBegin)
+
+	assert.Equal(t, &Counts{Sentences: 2, Words: 9, Issues: 2, ScoredIssues: 2}, result.Counts)
+	assert.Equal(t, []*Goal{{ID: "CLARITY", Issues: 2}}, result.Goals)
+
+	// Scores are 70 for 3 words and 40 for 6 words.
+	assert.Equal(t, 50, result.Quality.Score)
+	assert.Equal(t, "red", result.Quality.Status)
+	assert.Equal(t, []*Score{{"average", 50}, {"minimum", 40}}, result.Quality.ScoresByStrategy)
+	assert.Equal(t, []*Score{{"CLARITY", 50}}, result.Quality.ScoresByGoal)
+}
+
+func TestCheckChunkedSmallContent(t *testing.T) {
+	checker := &chunkChecker{}
+
+	_, err := CheckChunked(context.Background(), checker, &SubmitCheckOptions{Content: "Small text."}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Small text."}, checker.contents)
+}
+
+func TestCheckChunkedWithPartialRanges(t *testing.T) {
+	content := "Good sentence one.\n\nBad sentence number two is longer."
+	checker := &chunkChecker{}
+
+	opts := &SubmitCheckOptions{
+		Content: content,
+		CheckOptions: &CheckOptions{
+			PartialCheckRanges: []*PartialCheckRange{{24, 30}},
+		},
+	}
+	result, err := CheckChunked(context.Background(), checker, opts, &ChunkOptions{MaxSize: 40})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"Bad sentence number two is longer."}, checker.contents)
+	assert.Len(t, result.Issues, 1)
+	assert.Equal(t, 20, result.Issues[0].PositionalInformation.Matches[0].OriginalBegin)
+	assert.Equal(t, []*PartialCheckRange{{24, 30}}, opts.CheckOptions.PartialCheckRanges)
+}
+
+func TestCheckChunkedWithError(t *testing.T) {
+	checker := &chunkChecker{err: errors.New("platform down")}
+
+	_, err := CheckChunked(context.Background(), checker,
+		&SubmitCheckOptions{Content: "One sentence. Two sentences. Three sentences."}, &ChunkOptions{MaxSize: 15})
+	assert.EqualError(t, err, "platform down")
+}
+
+func TestCheckChunkedEncodedContent(t *testing.T) {
+	opts := &SubmitCheckOptions{}
+	opts.SetBinaryContent([]byte(strings.Repeat("x", 100)))
+
+	_, err := CheckChunked(context.Background(), &chunkChecker{}, opts, &ChunkOptions{MaxSize: 10})
+	assert.ErrorContains(t, err, "encoded content can't be split")
+}
+
+func TestMergeCheckResultsKeywords(t *testing.T) {
+	chunks := []*Chunk{{Offset: 0}, {Offset: 100}}
+	results := []*CheckResult{
+		{Keywords: &Keywords{Discovered: []*Keyword{
+			{Keyword: "error", Count: 1, Density: 2, Occurrences: []*PositionalInformation{{Matches: []*Match{{OriginalBegin: 5, OriginalEnd: 10}}}}},
+		}}},
+		{Keywords: &Keywords{Discovered: []*Keyword{
+			{Keyword: "error", Count: 2, Density: 3, Occurrences: []*PositionalInformation{{Matches: []*Match{{OriginalBegin: 1, OriginalEnd: 6}}}}},
+		}}},
+	}
+
+	merged := MergeCheckResults(chunks, results)
+	assert.Len(t, merged.Keywords.Discovered, 1)
+
+	keyword := merged.Keywords.Discovered[0]
+	assert.Equal(t, 3, keyword.Count)
+	assert.Equal(t, 3., keyword.Density)
+	assert.Equal(t, 101, keyword.Occurrences[1].Matches[0].OriginalBegin)
+	assert.Equal(t, 5, results[0].Keywords.Discovered[0].Occurrences[0].Matches[0].OriginalBegin)
+}
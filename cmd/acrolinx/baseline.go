@@ -0,0 +1,90 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/acrolinx/go-acrolinx"
+)
+
+// baselineFlags hold the flags reading and writing baseline files.
+type baselineFlags struct {
+	path      string
+	writePath string
+}
+
+func (bf *baselineFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&bf.path, "baseline", "", "only report issues not in the baseline `file`; the quality gate then only applies issue limits, as scores include baseline issues")
+	fs.StringVar(&bf.writePath, "write-baseline", "", "record the issues found in the baseline `file`")
+}
+
+// load reads the baseline file, or returns nil if none is given.
+func (bf *baselineFlags) load() (*acrolinx.Baseline, error) {
+	if bf.path == "" {
+		return nil, nil
+	}
+
+	f, err := os.Open(bf.path)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading baseline: %w", err)
+	}
+	defer f.Close()
+
+	return acrolinx.LoadBaseline(f)
+}
+
+// applyBaseline writes the baseline file if requested, and removes the issues
+// in baseline from docs. It prints the number of fixed issues of every
+// document.
+func (c *cli) applyBaseline(bf *baselineFlags, baseline *acrolinx.Baseline, docs []*acrolinx.CheckedDocument) ([]*acrolinx.CheckedDocument, error) {
+	if bf.writePath != "" {
+		if err := writeBaseline(bf.writePath, acrolinx.NewBaseline(docs)); err != nil {
+			return nil, err
+		}
+	}
+
+	if baseline == nil {
+		return docs, nil
+	}
+
+	for _, doc := range docs {
+		if fixed := len(baseline.Compare(doc).Fixed); fixed > 0 {
+			fmt.Fprintf(c.stderr, "%s: %d baseline issues fixed\n", doc.Path, fixed)
+		}
+	}
+
+	return baseline.FilterNew(docs), nil
+}
+
+// baselinePolicy returns the issue limits of policy. Scores and
+// statuses are computed by the platform including the issues in the
+// baseline, so their thresholds are dropped with a warning.
+func (c *cli) baselinePolicy(policy *acrolinx.QualityPolicy) *acrolinx.QualityPolicy {
+	if policy.MinScore > 0 || len(policy.MinGoalScores) > 0 || len(policy.AllowedStatuses) > 0 {
+		fmt.Fprintln(c.stderr, "acrolinx: ignoring score and status thresholds with -baseline, as scores include baseline issues")
+	}
+
+	return &acrolinx.QualityPolicy{
+		MaxGoalIssues:    policy.MaxGoalIssues,
+		MaxScoringIssues: policy.MaxScoringIssues,
+	}
+}
+
+func writeBaseline(path string, baseline *acrolinx.Baseline) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("Error writing baseline: %w", err)
+	}
+
+	if err := baseline.Save(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("Error writing baseline: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,377 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/acrolinx/go-acrolinx"
+)
+
+func (c *cli) check(args []string) error {
+	fs := c.flagSet("check", "[flags] [file|dir|glob|-]...")
+	var conn connection
+	conn.register(fs)
+	var out output
+	out.register(fs)
+	profile := fs.String("profile", "", "guidance profile `ID or name`, defaults to the platform default")
+	language := fs.String("language", "", "use the guidance profile for the language `ID`")
+	contentFormat := fs.String("content-format", "", "content `format`, detected from the file name by default")
+	checkType := fs.String("check-type", "", "check `type`, defaults to the platform default")
+	batchID := fs.String("batch-id", "", "`ID` grouping the checks of this run")
+	stdinName := fs.String("stdin-filename", "stdin", "`name` reported for content read from stdin")
+	var gate policyFlags
+	gate.register(fs)
+	var bf baselineFlags
+	bf.register(fs)
+	var df diffFlags
+	df.register(fs)
+	ignoreDirectives := fs.Bool("ignore-directives", false, "report issues disabled by acrolinx-disable comments")
+	reportUnused := fs.Bool("report-unused-directives", false, "warn about acrolinx-disable comments that disable no issue")
+	timeout := fs.Duration("timeout", 5*time.Minute, "maximum `duration` of each check")
+	concurrency := fs.Int("concurrency", 4, "maximum `number` of files of a directory checked at the same time")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := out.validate(); err != nil {
+		return err
+	}
+
+	policy, err := gate.load()
+	if err != nil {
+		return err
+	}
+
+	baseline, err := bf.load()
+	if err != nil {
+		return err
+	}
+	if baseline != nil {
+		policy = c.baselinePolicy(policy)
+	}
+
+	diffs, err := df.load(c.ctx)
+	if err != nil {
+		return err
+	}
+
+	paths, err := expandPaths(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	client, err := conn.client()
+	if err != nil {
+		return err
+	}
+
+	caps, _, err := client.Checking.GetCapabilities(nil)
+	if err != nil {
+		return fmt.Errorf("Error getting capabilities: %w", err)
+	}
+
+	profileID, err := selectProfile(caps, *profile, *language)
+	if err != nil {
+		return err
+	}
+
+	resolver, err := acrolinx.NewContentFormatResolver(caps)
+	if err != nil {
+		return err
+	}
+
+	fc := &fileChecker{
+		checker: client.Checking,
+		caps:    caps,
+		df:      &df,
+		diffs:   diffs,
+		timeout: *timeout,
+		seen:    make(map[string]bool),
+	}
+	baseOpts := &acrolinx.CheckOptions{
+		GuidanceProfileID: profileID,
+		ContentFormat:     *contentFormat,
+		CheckType:         *checkType,
+		BatchID:           *batchID,
+	}
+
+	var docs []*acrolinx.CheckedDocument
+	for _, path := range paths {
+		if info, err := os.Stat(path); path != "-" && err == nil && info.IsDir() {
+			dirDocs, err := c.checkDir(fc, path, &acrolinx.WalkOptions{
+				Exclude:      []string{".*"},
+				CheckOptions: baseOpts,
+				Formats:      resolver,
+				Concurrency:  *concurrency,
+			})
+			if err != nil {
+				return err
+			}
+			docs = append(docs, dirDocs...)
+			continue
+		}
+
+		name := path
+		if path == "-" {
+			name = *stdinName
+		}
+		if fc.seen[name] {
+			continue
+		}
+
+		content, err := c.readInput(path)
+		if err != nil {
+			return err
+		}
+
+		checkOpts := *baseOpts
+		opts := &acrolinx.SubmitCheckOptions{
+			CheckOptions: &checkOpts,
+			Document:     &acrolinx.Document{Reference: filepath.ToSlash(name)},
+		}
+		if *contentFormat == "" {
+			resolver.Apply(opts, name, content)
+		}
+		if err := opts.SetFileContent(content); err != nil {
+			return fmt.Errorf("Error checking %s: %w", name, err)
+		}
+
+		result, err := fc.Check(c.ctx, opts)
+		if errors.Is(err, errSkipped) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("Error checking %s: %w", name, err)
+		}
+		fc.seen[name] = true
+
+		doc := &acrolinx.CheckedDocument{Path: name, Content: string(content), Result: result}
+		if opts.ContentEncoding == acrolinx.ContentEncodingBase64 {
+			doc.Content = ""
+		}
+		docs = append(docs, doc)
+	}
+
+	if !*ignoreDirectives {
+		for _, doc := range docs {
+			c.applyDirectives(doc, *reportUnused)
+		}
+	}
+
+	docs, err = c.applyBaseline(&bf, baseline, docs)
+	if err != nil {
+		return err
+	}
+
+	if err := out.write(c, docs); err != nil {
+		return err
+	}
+
+	return c.checkPolicy(docs, policy)
+}
+
+// errSkipped is returned by fileChecker for files that need no check.
+var errSkipped = errors.New("skipped")
+
+// fileChecker checks the files given on the command line. Files already
+// checked and, with diffs, binary files and files without changes are
+// skipped; only the changed lines of the others are checked.
+type fileChecker struct {
+	checker acrolinx.Checker
+	caps    *acrolinx.Capabilities
+	df      *diffFlags
+	diffs   []*acrolinx.FileDiff
+	timeout time.Duration
+	// seen holds the names of the files checked so far. It is only
+	// written between checks.
+	seen map[string]bool
+}
+
+// Check checks the file named by the document reference of opts.
+func (fc *fileChecker) Check(ctx context.Context, opts *acrolinx.SubmitCheckOptions) (*acrolinx.CheckResult, error) {
+	name := filepath.FromSlash(opts.Document.Reference)
+	if fc.seen[name] {
+		return nil, errSkipped
+	}
+
+	binary := opts.ContentEncoding == acrolinx.ContentEncodingBase64
+	if binary && !fc.caps.SupportsContentEncoding(acrolinx.ContentEncodingBase64) {
+		return nil, fmt.Errorf("%s documents must be sent base64 encoded, which the platform doesn't support", opts.CheckOptions.ContentFormat)
+	}
+
+	// Diffs have no changed lines for binary documents.
+	var ranges []*acrolinx.PartialCheckRange
+	if fc.diffs != nil {
+		if binary {
+			return nil, errSkipped
+		}
+		var changed bool
+		if ranges, changed = fc.df.ranges(fc.diffs, name, opts.Content); !changed {
+			return nil, errSkipped
+		}
+		opts.CheckOptions.PartialCheckRanges = ranges
+	}
+
+	if err := acrolinx.ValidateSubmitCheckOptions(opts, fc.caps); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, fc.timeout)
+	defer cancel()
+
+	result, err := fc.checker.Check(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if ranges != nil {
+		result.Issues = acrolinx.FilterIssuesInRanges(result.Issues, ranges)
+	}
+
+	return result, nil
+}
+
+// checkDir checks the files of dir concurrently, except hidden files and
+// those ignored by .gitignore, and returns them sorted by path. Files
+// checked before are skipped.
+func (c *cli) checkDir(fc *fileChecker, dir string, opts *acrolinx.WalkOptions) ([]*acrolinx.CheckedDocument, error) {
+	ctx, cancel := context.WithCancel(c.ctx)
+	defer cancel()
+
+	opts.ReferencePrefix = referencePrefix(dir)
+	results, err := acrolinx.CheckFS(ctx, fc, os.DirFS(dir), opts)
+	if err != nil {
+		return nil, fmt.Errorf("Error expanding %s: %w", dir, err)
+	}
+
+	var docs []*acrolinx.CheckedDocument
+	for r := range results {
+		if errors.Is(r.Err, errSkipped) {
+			continue
+		}
+		if r.Err != nil {
+			return nil, fmt.Errorf("Error checking %s: %w", dir, r.Err)
+		}
+		docs = append(docs, r.Document)
+	}
+	if err := c.ctx.Err(); err != nil {
+		return nil, fmt.Errorf("Error checking %s: %w", dir, err)
+	}
+
+	sort.Slice(docs, func(i, j int) bool { return docs[i].Path < docs[j].Path })
+	for _, doc := range docs {
+		doc.Path = filepath.Join(dir, filepath.FromSlash(doc.Path))
+		fc.seen[doc.Path] = true
+	}
+
+	return docs, nil
+}
+
+// referencePrefix returns the prefix turning the paths of files in dir
+// into slash-separated paths including dir.
+func referencePrefix(dir string) string {
+	dir = filepath.Clean(dir)
+	if dir == "." {
+		return ""
+	}
+
+	return strings.TrimSuffix(filepath.ToSlash(dir), "/") + "/"
+}
+
+// applyDirectives removes the issues disabled by directives in the
+// content of doc, and optionally warns about unused directives.
+func (c *cli) applyDirectives(doc *acrolinx.CheckedDocument, reportUnused bool) {
+	suppressed := acrolinx.ParseSuppressions(doc.Content).Filter(doc.Result.Issues)
+	doc.Result.Issues = suppressed.Issues
+
+	if reportUnused {
+		for _, d := range suppressed.Unused {
+			fmt.Fprintf(c.stderr, "%s:%d: unused acrolinx-%s directive\n", doc.Path, d.Line, d.Kind)
+		}
+	}
+}
+
+// readInput reads the file at path, or stdin if path is "-".
+func (c *cli) readInput(path string) ([]byte, error) {
+	var content []byte
+	var err error
+	if path == "-" {
+		content, err = io.ReadAll(c.stdin)
+	} else {
+		content, err = os.ReadFile(path)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Error reading %s: %w", path, err)
+	}
+
+	return content, nil
+}
+
+// expandPaths expands the glob patterns in args. Directories are kept
+// to be checked by checkDir. Without arguments, stdin is checked.
+func expandPaths(args []string) ([]string, error) {
+	if len(args) == 0 {
+		return []string{"-"}, nil
+	}
+
+	var paths []string
+	seen := make(map[string]bool)
+	add := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+
+	for _, arg := range args {
+		if arg == "-" || !strings.ContainsAny(arg, "*?[") {
+			add(arg)
+			continue
+		}
+
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return nil, fmt.Errorf("Error expanding %s: %w", arg, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("Error expanding %s: no matching files", arg)
+		}
+
+		sort.Strings(matches)
+		for _, m := range matches {
+			add(m)
+		}
+	}
+
+	return paths, nil
+}
+
+// selectProfile returns the ID of the guidance profile given by its ID
+// or display name, or else the profile for language. It returns an
+// empty ID to use the platform default.
+func selectProfile(caps *acrolinx.Capabilities, profile string, language string) (string, error) {
+	switch {
+	case profile != "":
+		if p := caps.GuidanceProfileByID(profile); p != nil {
+			return p.ID, nil
+		}
+		if p := caps.GuidanceProfileByDisplayName(profile); p != nil {
+			return p.ID, nil
+		}
+		return "", fmt.Errorf("Error selecting guidance profile: unknown profile %q", profile)
+	case language != "":
+		if p := caps.GuidanceProfileByLanguage(language); p != nil {
+			return p.ID, nil
+		}
+		return "", fmt.Errorf("Error selecting guidance profile: no profile for language %q", language)
+	}
+
+	return "", nil
+}
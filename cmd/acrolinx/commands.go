@@ -0,0 +1,200 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/acrolinx/go-acrolinx"
+)
+
+func (c *cli) signIn(args []string) error {
+	fs := c.flagSet("signin", "[flags]")
+	var conn connection
+	conn.register(fs)
+	username := fs.String("username", os.Getenv("ACROLINX_USERNAME"), "user `name`, defaults to $ACROLINX_USERNAME")
+	password := fs.String("password", "", "`password`, read from stdin if not given")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := checkArgs(fs, 0); err != nil {
+		return err
+	}
+
+	if *username == "" {
+		return fmt.Errorf("Error signing in: no user name, set -username or ACROLINX_USERNAME")
+	}
+
+	if *password == "" {
+		line, err := readLine(c.stdin)
+		if err != nil {
+			return fmt.Errorf("Error reading password: %w", err)
+		}
+		*password = line
+	}
+
+	client, err := conn.client()
+	if err != nil {
+		return err
+	}
+
+	if err := client.SignIn(*username, *password); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(c.stdout, client.AccessToken())
+	return nil
+}
+
+func (c *cli) capabilities(args []string) error {
+	fs := c.flagSet("capabilities", "[flags]")
+	var conn connection
+	conn.register(fs)
+	locale := fs.String("locale", "", "`locale` of display names")
+	asJSON := fs.Bool("json", false, "print the capabilities as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := checkArgs(fs, 0); err != nil {
+		return err
+	}
+
+	client, err := conn.client()
+	if err != nil {
+		return err
+	}
+
+	caps, _, err := client.Checking.GetCapabilities(&acrolinx.GetCapabilitiesOptions{Locale: *locale})
+	if err != nil {
+		return fmt.Errorf("Error getting capabilities: %w", err)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(c.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(caps)
+	}
+
+	tw := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "Guidance profiles:")
+	for _, p := range caps.GuidanceProfiles {
+		language := ""
+		if p.Language != nil {
+			language = p.Language.ID
+		}
+
+		def := ""
+		if p.ID == caps.DefaultGuidanceProfileID {
+			def = "(default)"
+		}
+
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", p.ID, p.DisplayName, language, def)
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("Error writing capabilities: %w", err)
+	}
+
+	formats := make([]string, len(caps.ContentFormats))
+	for i, f := range caps.ContentFormats {
+		formats[i] = f.ID
+	}
+
+	fmt.Fprintf(c.stdout, "Content formats: %s\n", strings.Join(formats, ", "))
+	fmt.Fprintf(c.stdout, "Content encodings: %s\n", strings.Join(caps.ContentEncodings, ", "))
+	fmt.Fprintf(c.stdout, "Check types: %s\n", strings.Join(caps.CheckTypes, ", "))
+	fmt.Fprintf(c.stdout, "Report types: %s\n", strings.Join(caps.ReportTypes, ", "))
+
+	return nil
+}
+
+func (c *cli) result(args []string) error {
+	fs := c.flagSet("result", "[flags] <check-id>")
+	var conn connection
+	conn.register(fs)
+	var out output
+	out.register(fs)
+	wait := fs.Bool("wait", false, "wait until the check has finished")
+	file := fs.String("file", "", "checked `file`, needed to report the lines and columns of issues")
+	var gate policyFlags
+	gate.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := checkArgs(fs, 1); err != nil {
+		return err
+	}
+	if err := out.validate(); err != nil {
+		return err
+	}
+
+	policy, err := gate.load()
+	if err != nil {
+		return err
+	}
+
+	client, err := conn.client()
+	if err != nil {
+		return err
+	}
+
+	check := &acrolinx.Check{ID: fs.Arg(0)}
+
+	var result *acrolinx.CheckResult
+	if *wait {
+		result, _, err = client.Checking.WaitForCheckResult(c.ctx, check)
+	} else {
+		result, _, err = client.Checking.GetCheckResult(check)
+	}
+	if err != nil {
+		return err
+	}
+
+	if p := result.Progress; p != nil {
+		return fmt.Errorf("Error getting result: check %s is still running (%d%%): %s", check.ID, p.Percent, p.Message)
+	}
+
+	doc := &acrolinx.CheckedDocument{Path: *file, Result: result}
+	if *file != "" {
+		content, err := os.ReadFile(*file)
+		if err != nil {
+			return fmt.Errorf("Error reading %s: %w", *file, err)
+		}
+		doc.Content = string(content)
+	} else if result.Document != nil && result.Document.DisplayInfo != nil {
+		doc.Path = result.Document.DisplayInfo.Reference
+	}
+
+	docs := []*acrolinx.CheckedDocument{doc}
+	if err := out.write(c, docs); err != nil {
+		return err
+	}
+
+	return c.checkPolicy(docs, policy)
+}
+
+func (c *cli) cancel(args []string) error {
+	fs := c.flagSet("cancel", "[flags] <check-id>")
+	var conn connection
+	conn.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := checkArgs(fs, 1); err != nil {
+		return err
+	}
+
+	client, err := conn.client()
+	if err != nil {
+		return err
+	}
+
+	cancelled, _, err := client.Checking.CancelCheck(&acrolinx.Check{ID: fs.Arg(0)})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "Cancelled check %s\n", cancelled.ID)
+	return nil
+}
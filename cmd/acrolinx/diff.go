@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/acrolinx/go-acrolinx"
+)
+
+var alignments = map[string]acrolinx.Alignment{
+	"sentence":  acrolinx.AlignSentences,
+	"paragraph": acrolinx.AlignParagraphs,
+	"line":      acrolinx.AlignLines,
+}
+
+// diffFlags hold the flags limiting checks to changed lines.
+type diffFlags struct {
+	revisions string
+	file      string
+	align     string
+}
+
+func (df *diffFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&df.revisions, "diff", "", "only check lines changed since the git `revision`, or between revisions given as A..B")
+	fs.StringVar(&df.file, "diff-file", "", "only check lines changed by the unified diff in `file`")
+	fs.StringVar(&df.align, "diff-align", "sentence", "extend changed lines to the enclosing `unit`: sentence, paragraph or line")
+}
+
+// load returns the changed files, or nil if all lines are checked.
+func (df *diffFlags) load(ctx context.Context) ([]*acrolinx.FileDiff, error) {
+	if _, ok := alignments[df.align]; !ok {
+		return nil, fmt.Errorf("Error: unknown -diff-align %q", df.align)
+	}
+
+	switch {
+	case df.revisions != "" && df.file != "":
+		return nil, fmt.Errorf("Error: -diff and -diff-file are mutually exclusive")
+	case df.revisions != "":
+		from, to, err := parseRevisions(df.revisions)
+		if err != nil {
+			return nil, err
+		}
+		diffs, err := acrolinx.GitDiff(ctx, "", from, to)
+		if err != nil {
+			return nil, err
+		}
+		return nonNil(diffs), nil
+	case df.file != "":
+		data, err := os.ReadFile(df.file)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading diff: %w", err)
+		}
+		diffs, err := acrolinx.ParseUnifiedDiff(string(data))
+		if err != nil {
+			return nil, err
+		}
+		return nonNil(diffs), nil
+	}
+
+	return nil, nil
+}
+
+// parseRevisions splits a revision or a range A..B, where an omitted
+// side defaults to HEAD as in git. A single revision is compared to the
+// working tree.
+func parseRevisions(revisions string) (string, string, error) {
+	if strings.Contains(revisions, "...") {
+		return "", "", fmt.Errorf("Error: -diff doesn't support A...B, use A..B or a single revision")
+	}
+
+	from, to, ok := strings.Cut(revisions, "..")
+	if !ok {
+		return revisions, "", nil
+	}
+	if from == "" && to == "" {
+		return "", "", fmt.Errorf("Error: invalid -diff range %q", revisions)
+	}
+	if from == "" {
+		from = "HEAD"
+	}
+	if to == "" {
+		to = "HEAD"
+	}
+
+	return from, to, nil
+}
+
+// nonNil distinguishes empty diffs, where nothing is checked, from no
+// diff at all.
+func nonNil(diffs []*acrolinx.FileDiff) []*acrolinx.FileDiff {
+	if diffs == nil {
+		return []*acrolinx.FileDiff{}
+	}
+
+	return diffs
+}
+
+// ranges returns the partial check ranges of the file at path, or
+// false if none of its lines changed. Paths in diffs are relative to
+// the working directory.
+func (df *diffFlags) ranges(diffs []*acrolinx.FileDiff, path string, content string) ([]*acrolinx.PartialCheckRange, bool) {
+	if abs, err := filepath.Abs(path); err == nil {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, abs); err == nil {
+				path = rel
+			}
+		}
+	}
+
+	d := acrolinx.FindFileDiff(diffs, filepath.ToSlash(filepath.Clean(path)))
+	if d == nil {
+		return nil, false
+	}
+
+	ranges := d.PartialCheckRanges(content, alignments[df.align])
+	return ranges, len(ranges) > 0
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRevisions(t *testing.T) {
+	tests := []struct {
+		revisions string
+		from      string
+		to        string
+	}{
+		{"main", "main", ""},
+		{"main..feature", "main", "feature"},
+		{"..feature", "HEAD", "feature"},
+		{"main..", "main", "HEAD"},
+	}
+
+	for _, tt := range tests {
+		from, to, err := parseRevisions(tt.revisions)
+		assert.NoError(t, err, tt.revisions)
+		assert.Equal(t, tt.from, from, tt.revisions)
+		assert.Equal(t, tt.to, to, tt.revisions)
+	}
+
+	for _, revisions := range []string{"..", "main...feature", "...feature"} {
+		_, _, err := parseRevisions(revisions)
+		assert.Error(t, err, revisions)
+	}
+}
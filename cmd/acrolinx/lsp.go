@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/acrolinx/go-acrolinx"
+)
+
+func (c *cli) lsp(args []string) error {
+	fs := c.flagSet("lsp", "[flags]")
+	var conn connection
+	conn.register(fs)
+	profile := fs.String("profile", "", "guidance profile `ID or name`, defaults to the platform default")
+	language := fs.String("language", "", "use the guidance profile for the language `ID`")
+	checkType := fs.String("check-type", "", "check `type`, defaults to the platform default")
+	scope := fs.String("dictionary-scope", "", "`scope` of the dictionary words are added to, defaults to the platform default")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := checkArgs(fs, 0); err != nil {
+		return err
+	}
+
+	client, err := conn.client()
+	if err != nil {
+		return err
+	}
+
+	caps, _, err := client.Checking.GetCapabilities(nil)
+	if err != nil {
+		return fmt.Errorf("Error getting capabilities: %w", err)
+	}
+
+	profileID, err := selectProfile(caps, *profile, *language)
+	if err != nil {
+		return err
+	}
+
+	resolver, err := acrolinx.NewContentFormatResolver(caps)
+	if err != nil {
+		return err
+	}
+
+	server := acrolinx.NewLanguageServer(client.Checking, &acrolinx.LanguageServerOptions{
+		CheckOptions: &acrolinx.CheckOptions{
+			GuidanceProfileID: profileID,
+			CheckType:         *checkType,
+		},
+		Formats:         resolver,
+		Dictionary:      client.Dictionary,
+		DictionaryScope: *scope,
+	})
+
+	return server.Serve(c.ctx, c.stdin, c.stdout)
+}
@@ -0,0 +1,189 @@
+// Command acrolinx checks documents with an Acrolinx platform and
+// reports the results in several formats.
+//
+// The platform URL, client signature and access token are read from
+// the environment variables ACROLINX_URL, ACROLINX_SIGNATURE and
+// ACROLINX_ACCESS_TOKEN, or given with the -url, -signature and -token
+// flags.
+//
+// The exit code is 0 on success, 1 if a document failed the quality
+// gate and 2 on errors.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/acrolinx/go-acrolinx"
+)
+
+const (
+	exitOK          = 0
+	exitQualityGate = 1
+	exitError       = 2
+
+	// exitWithoutShutdown is the exit code of the language server if
+	// the client didn't request a shutdown, as the protocol specifies.
+	exitWithoutShutdown = 1
+)
+
+// errQualityGate is returned by commands if a document failed the
+// quality gate. The reasons have already been printed.
+var errQualityGate = errors.New("quality gate failed")
+
+const usage = `usage: acrolinx <command> [flags] [args]
+
+Commands:
+  signin        sign in with user name and password and print the access token
+  capabilities  list guidance profiles, content formats and check types
+  check         check files, directories, glob patterns or stdin
+  watch         check the files of a directory whenever they change
+  lsp           run a language server publishing issues as diagnostics
+  result        get the result of a check by its ID
+  cancel        cancel a check by its ID
+
+Run acrolinx <command> -h for the flags of a command.
+`
+
+type cli struct {
+	ctx    context.Context
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// run executes the command given by args and returns the exit code.
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitError
+	}
+
+	c := &cli{ctx, stdin, stdout, stderr}
+
+	var err error
+	switch args[0] {
+	case "signin":
+		err = c.signIn(args[1:])
+	case "capabilities":
+		err = c.capabilities(args[1:])
+	case "check":
+		err = c.check(args[1:])
+	case "watch":
+		err = c.watch(args[1:])
+	case "lsp":
+		err = c.lsp(args[1:])
+	case "result":
+		err = c.result(args[1:])
+	case "cancel":
+		err = c.cancel(args[1:])
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	default:
+		fmt.Fprintf(stderr, "acrolinx: unknown command %q\n\n%s", args[0], usage)
+		return exitError
+	}
+
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.Is(err, errQualityGate):
+		return exitQualityGate
+	case errors.Is(err, acrolinx.ErrExitWithoutShutdown):
+		return exitWithoutShutdown
+	}
+
+	fmt.Fprintf(stderr, "acrolinx: %v\n", err)
+	return exitError
+}
+
+// flagSet creates the flag set of a command. Errors are returned
+// instead of exiting.
+func (c *cli) flagSet(name string, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "usage: acrolinx %s %s\n\nFlags:\n", name, args)
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
+
+// connection holds the flags needed to connect to the platform.
+type connection struct {
+	url       string
+	signature string
+	token     string
+}
+
+func (conn *connection) register(fs *flag.FlagSet) {
+	fs.StringVar(&conn.url, "url", os.Getenv("ACROLINX_URL"), "platform `URL`, defaults to $ACROLINX_URL")
+	fs.StringVar(&conn.signature, "signature", os.Getenv("ACROLINX_SIGNATURE"), "client `signature`, defaults to $ACROLINX_SIGNATURE")
+	fs.StringVar(&conn.token, "token", os.Getenv("ACROLINX_ACCESS_TOKEN"), "access `token`, defaults to $ACROLINX_ACCESS_TOKEN")
+}
+
+func (conn *connection) client() (*acrolinx.Client, error) {
+	switch {
+	case conn.url == "":
+		return nil, errors.New("Error creating client: no platform URL, set -url or ACROLINX_URL")
+	case conn.signature == "":
+		return nil, errors.New("Error creating client: no client signature, set -signature or ACROLINX_SIGNATURE")
+	}
+
+	var options []acrolinx.ClientOptionFunc
+	if conn.token != "" {
+		options = append(options, acrolinx.WithAPIToken(conn.token))
+	}
+
+	return acrolinx.NewClient(conn.signature, conn.url, options...)
+}
+
+// checkArgs fails if the number of positional arguments of fs is not n.
+func checkArgs(fs *flag.FlagSet, n int) error {
+	if fs.NArg() != n {
+		fs.Usage()
+		return fmt.Errorf("Error parsing arguments: expected %d arguments, got %d", n, fs.NArg())
+	}
+
+	return nil
+}
+
+// readLine reads a single line from r without its line ending.
+func readLine(r io.Reader) (string, error) {
+	var b strings.Builder
+	buf := make([]byte, 1)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if buf[0] == '\n' {
+				break
+			}
+			b.WriteByte(buf[0])
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "\r"), nil
+}
@@ -0,0 +1,691 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/acrolinx/go-acrolinx"
+	"github.com/stretchr/testify/assert"
+)
+
+var testCapabilities = &acrolinx.Capabilities{
+	DefaultGuidanceProfileID: "en-1",
+	GuidanceProfiles: []*acrolinx.GuidanceProfile{
+		{ID: "en-1", DisplayName: "English", Language: &acrolinx.Language{ID: "en", DisplayName: "English"}},
+		{ID: "de-1", DisplayName: "Deutsch", Language: &acrolinx.Language{ID: "de", DisplayName: "German"}},
+	},
+	ContentFormats: []*acrolinx.ContentFormat{
+		{ID: "TEXT", DisplayName: "Text"},
+		{ID: "MARKDOWN", DisplayName: "Markdown"},
+		{ID: "MS_OFFICE", DisplayName: "Microsoft Office"},
+	},
+	ContentEncodings: []string{"none", "base64"},
+	CheckTypes:       []string{"batch", "automated"},
+	ReportTypes:      []string{"scorecard"},
+}
+
+// fakePlatform serves the parts of the platform API used by the CLI.
+// Documents containing "bad" score 40, all others 100.
+type fakePlatform struct {
+	server *httptest.Server
+
+	mu        sync.Mutex
+	caps      *acrolinx.Capabilities
+	submitted []*acrolinx.SubmitCheckOptions
+	signIn    *acrolinx.Credentials
+}
+
+func newFakePlatform(t *testing.T) *fakePlatform {
+	p := &fakePlatform{caps: testCapabilities}
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /dashboard/api/signin/authenticate", func(w http.ResponseWriter, r *http.Request) {
+		var creds acrolinx.Credentials
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&creds))
+		p.mu.Lock()
+		p.signIn = &creds
+		p.mu.Unlock()
+		writeJSONResponse(t, w, map[string]string{"accessToken": "secret-token"})
+	})
+
+	mux.HandleFunc("GET /api/v1/checking/capabilities", func(w http.ResponseWriter, r *http.Request) {
+		p.mu.Lock()
+		caps := p.caps
+		p.mu.Unlock()
+		writeJSONResponse(t, w, map[string]interface{}{"data": caps})
+	})
+
+	mux.HandleFunc("POST /api/v1/checking/checks", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-signature", r.Header.Get("X-Acrolinx-Client"))
+		assert.Equal(t, "test-token", r.Header.Get("X-Acrolinx-Auth"))
+
+		var opts acrolinx.SubmitCheckOptions
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&opts))
+
+		p.mu.Lock()
+		p.submitted = append(p.submitted, &opts)
+		id := len(p.submitted) - 1
+		p.mu.Unlock()
+
+		writeJSONResponse(t, w, map[string]interface{}{"data": acrolinx.Check{ID: fmt.Sprintf("check-%d", id)}})
+	})
+
+	mux.HandleFunc("GET /api/v1/checking/checks/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		if id == "running" {
+			writeJSONResponse(t, w, map[string]interface{}{
+				"progress": acrolinx.Progress{Percent: 30, Message: "Still processing", RetryAfter: 1},
+			})
+			return
+		}
+
+		var n int
+		_, err := fmt.Sscanf(id, "check-%d", &n)
+		assert.NoError(t, err)
+
+		p.mu.Lock()
+		opts := p.submitted[n]
+		p.mu.Unlock()
+
+		writeJSONResponse(t, w, map[string]interface{}{"data": fakeResult(id, opts.Content)})
+	})
+
+	mux.HandleFunc("DELETE /api/v1/checking/checks/{id}", func(w http.ResponseWriter, r *http.Request) {
+		writeJSONResponse(t, w, map[string]interface{}{"data": acrolinx.CancelledCheck{ID: r.PathValue("id")}})
+	})
+
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+
+	t.Setenv("ACROLINX_URL", p.server.URL)
+	t.Setenv("ACROLINX_SIGNATURE", "test-signature")
+	t.Setenv("ACROLINX_ACCESS_TOKEN", "test-token")
+
+	return p
+}
+
+func fakeResult(id string, content string) *acrolinx.CheckResult {
+	result := &acrolinx.CheckResult{
+		ID:      id,
+		Quality: &acrolinx.Quality{Score: 100, Status: "green"},
+		Goals:   []*acrolinx.Goal{{ID: "CLARITY", DisplayName: "Clarity", Scoring: "required"}},
+	}
+
+	if i := strings.Index(content, "bad"); i >= 0 {
+		result.Quality = &acrolinx.Quality{Score: 40, Status: "red"}
+		result.Issues = []*acrolinx.Issue{{
+			GoalID:          "CLARITY",
+			InternalName:    "avoid_bad",
+			DisplayNameHTML: "Avoid bad",
+			PositionalInformation: &acrolinx.PositionalInformation{
+				Matches: []*acrolinx.Match{{OriginalPart: "bad", OriginalBegin: i, OriginalEnd: i + 3}},
+			},
+		}}
+	}
+
+	return result
+}
+
+func writeJSONResponse(t *testing.T, w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	assert.NoError(t, json.NewEncoder(w).Encode(v))
+}
+
+func runCLI(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	return dir
+}
+
+func TestRunUsage(t *testing.T) {
+	code, _, stderr := runCLI("")
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "usage: acrolinx <command>")
+
+	code, stdout, _ := runCLI("", "help")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "Commands:")
+
+	code, _, stderr = runCLI("", "frobnicate")
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, `unknown command "frobnicate"`)
+
+	code, _, stderr = runCLI("", "check", "-h")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stderr, "usage: acrolinx check [flags] [file|dir|glob|-]...")
+}
+
+func TestSignIn(t *testing.T) {
+	p := newFakePlatform(t)
+
+	code, stdout, stderr := runCLI("hunter2\n", "signin", "-username", "jane")
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "secret-token\n", stdout)
+	assert.Equal(t, &acrolinx.Credentials{Username: "jane", Password: "hunter2"}, p.signIn)
+
+	code, _, stderr = runCLI("", "signin")
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "no user name")
+}
+
+func TestCapabilities(t *testing.T) {
+	newFakePlatform(t)
+
+	code, stdout, stderr := runCLI("", "capabilities")
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, "en-1  English  en  (default)")
+	assert.Contains(t, stdout, "Content formats: TEXT, MARKDOWN, MS_OFFICE\n")
+	assert.Contains(t, stdout, "Check types: batch, automated\n")
+
+	code, stdout, _ = runCLI("", "capabilities", "-json")
+	assert.Equal(t, exitOK, code)
+
+	var caps acrolinx.Capabilities
+	assert.NoError(t, json.Unmarshal([]byte(stdout), &caps))
+	assert.Equal(t, testCapabilities, &caps)
+}
+
+func TestCheckFiles(t *testing.T) {
+	p := newFakePlatform(t)
+	dir := writeFiles(t, map[string]string{
+		"docs/a.md":  "# Fine\n",
+		"docs/b.txt": "Also fine.\n",
+		"docs/c.md":  "Not\nbad at all.\n",
+	})
+
+	code, stdout, stderr := runCLI("",
+		"check", "-format", "checkstyle", "-profile", "Deutsch", "-check-type", "automated",
+		filepath.Join(dir, "docs", "*.md"), filepath.Join(dir, "docs", "b.txt"), filepath.Join(dir, "docs", "a.md"),
+	)
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, `<error line="2" column="1" severity="error" message="Avoid bad" source="acrolinx.CLARITY.avoid_bad"></error>`)
+
+	assert.Len(t, p.submitted, 3)
+	assert.Equal(t, "MARKDOWN", p.submitted[0].CheckOptions.ContentFormat)
+	assert.Equal(t, "TEXT", p.submitted[2].CheckOptions.ContentFormat)
+	assert.Equal(t, "de-1", p.submitted[0].CheckOptions.GuidanceProfileID)
+	assert.Equal(t, "automated", p.submitted[0].CheckOptions.CheckType)
+	assert.Equal(t, filepath.ToSlash(filepath.Join(dir, "docs", "a.md")), p.submitted[0].Document.Reference)
+}
+
+func TestCheckDirectory(t *testing.T) {
+	p := newFakePlatform(t)
+	dir := writeFiles(t, map[string]string{
+		"docs/a.md":       "Not bad.\n",
+		"docs/sub/b.md":   "Fine.\n",
+		"docs/.hidden.md": "Hidden.\n",
+		"docs/draft.md":   "Draft.\n",
+		"docs/.gitignore": "draft.md\n",
+	})
+	docs := filepath.Join(dir, "docs")
+
+	code, stdout, stderr := runCLI("", "check", "-format", "json", "-concurrency", "2",
+		filepath.Join(docs, "sub", "b.md"), docs, docs,
+	)
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Len(t, p.submitted, 2)
+
+	var results []*jsonDocument
+	assert.NoError(t, json.Unmarshal([]byte(stdout), &results))
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, filepath.Join(docs, "sub", "b.md"), results[0].Path)
+		assert.Equal(t, filepath.Join(docs, "a.md"), results[1].Path)
+		assert.Len(t, results[1].Result.Issues, 1)
+	}
+
+	for _, opts := range p.submitted {
+		assert.Equal(t, "MARKDOWN", opts.CheckOptions.ContentFormat)
+		assert.True(t, strings.HasPrefix(opts.Document.Reference, filepath.ToSlash(docs)+"/"), opts.Document.Reference)
+	}
+}
+
+// concurrentChecker blocks every check until n checks run at the same
+// time.
+type concurrentChecker struct {
+	n       int
+	mu      sync.Mutex
+	running int
+	all     chan struct{}
+}
+
+func (c *concurrentChecker) Check(ctx context.Context, opts *acrolinx.SubmitCheckOptions) (*acrolinx.CheckResult, error) {
+	c.mu.Lock()
+	c.running++
+	if c.running == c.n {
+		close(c.all)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-c.all:
+		return fakeResult("check", opts.Content), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestCheckDirConcurrently(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"c.md": "bad", "a.md": "", "b.md": ""})
+	checker := &concurrentChecker{n: 3, all: make(chan struct{})}
+	fc := &fileChecker{checker: checker, caps: testCapabilities, timeout: 5 * time.Second, seen: map[string]bool{}}
+
+	c := &cli{ctx: context.Background(), stderr: io.Discard}
+	docs, err := c.checkDir(fc, dir, &acrolinx.WalkOptions{Concurrency: 3})
+	assert.NoError(t, err)
+	if assert.Len(t, docs, 3) {
+		assert.Equal(t, filepath.Join(dir, "a.md"), docs[0].Path)
+		assert.Equal(t, filepath.Join(dir, "b.md"), docs[1].Path)
+		assert.Equal(t, filepath.Join(dir, "c.md"), docs[2].Path)
+		assert.Len(t, docs[2].Result.Issues, 1)
+	}
+	assert.True(t, fc.seen[filepath.Join(dir, "c.md")])
+
+	docs, err = c.checkDir(fc, dir, &acrolinx.WalkOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, docs)
+}
+
+func TestReferencePrefix(t *testing.T) {
+	assert.Equal(t, "", referencePrefix("."))
+	assert.Equal(t, "docs/", referencePrefix("./docs/"))
+	assert.Equal(t, "/", referencePrefix("/"))
+}
+
+func TestCheckBinaryFiles(t *testing.T) {
+	p := newFakePlatform(t)
+	dir := writeFiles(t, map[string]string{
+		"slides.pptx": "PK\x03\x04\x00\xffbad",
+		"logo.png":    "\x89PNG\r\n\x1a\n",
+	})
+	slides := filepath.Join(dir, "slides.pptx")
+
+	code, stdout, stderr := runCLI("", "check", "-format", "json", slides)
+	assert.Equal(t, exitOK, code, stderr)
+	if assert.Len(t, p.submitted, 1) {
+		assert.Equal(t, "MS_OFFICE", p.submitted[0].CheckOptions.ContentFormat)
+		assert.Equal(t, acrolinx.ContentEncodingBase64, p.submitted[0].ContentEncoding)
+		data, err := p.submitted[0].BinaryContent()
+		assert.NoError(t, err)
+		assert.Equal(t, "PK\x03\x04\x00\xffbad", string(data))
+	}
+	var results []*jsonDocument
+	assert.NoError(t, json.Unmarshal([]byte(stdout), &results))
+	assert.Len(t, results, 1)
+
+	// Other binary files are skipped in directories but fail when given
+	// explicitly.
+	code, _, stderr = runCLI("", "check", dir)
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Len(t, p.submitted, 2)
+
+	code, _, stderr = runCLI("", "check", filepath.Join(dir, "logo.png"))
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "Error checking "+filepath.Join(dir, "logo.png")+": binary content in a text format")
+
+	caps := *testCapabilities
+	caps.ContentEncodings = []string{"none"}
+	p.mu.Lock()
+	p.caps = &caps
+	p.mu.Unlock()
+
+	for _, arg := range []string{slides, dir} {
+		code, _, stderr = runCLI("", "check", arg)
+		assert.Equal(t, exitError, code)
+		assert.Contains(t, stderr, "MS_OFFICE documents must be sent base64 encoded, which the platform doesn't support")
+	}
+	assert.Len(t, p.submitted, 2)
+}
+
+func TestCheckStdin(t *testing.T) {
+	p := newFakePlatform(t)
+
+	code, stdout, stderr := runCLI("This is bad.\n", "check", "-stdin-filename", "input.md", "-language", "en")
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, "input.md:1:9: error: Avoid bad [Clarity/avoid_bad]")
+
+	assert.Equal(t, "This is bad.\n", p.submitted[0].Content)
+	assert.Equal(t, "en-1", p.submitted[0].CheckOptions.GuidanceProfileID)
+	assert.Equal(t, "MARKDOWN", p.submitted[0].CheckOptions.ContentFormat)
+}
+
+func TestCheckMinScore(t *testing.T) {
+	newFakePlatform(t)
+	dir := writeFiles(t, map[string]string{"good.txt": "Good.", "bad.txt": "So bad."})
+
+	code, _, stderr := runCLI("", "check", "-min-score", "50", filepath.Join(dir, "good.txt"))
+	assert.Equal(t, exitOK, code, stderr)
+
+	code, _, stderr = runCLI("", "check", "-min-score", "50", filepath.Join(dir, "*.txt"))
+	assert.Equal(t, exitQualityGate, code)
+	assert.Equal(t, filepath.Join(dir, "bad.txt")+": score 40 is below the minimum of 50\n", stderr)
+}
+
+func TestCheckPolicy(t *testing.T) {
+	newFakePlatform(t)
+	dir := writeFiles(t, map[string]string{"a.txt": "Not bad.", "b.txt": "Good."})
+
+	code, _, stderr := runCLI("", "check",
+		"-max-scoring-issues", "required=0",
+		"-allowed-status", "green,yellow",
+		filepath.Join(dir, "*.txt"),
+	)
+	assert.Equal(t, exitQualityGate, code)
+	assert.Equal(t, filepath.Join(dir, "a.txt")+": status \"red\" is not one of green, yellow\n"+
+		filepath.Join(dir, "a.txt")+": 1 required issues exceed the maximum of 0\n", stderr)
+
+	code, _, stderr = runCLI("", "check", "-max-goal-issues", "CLARITY=1", filepath.Join(dir, "*.txt"))
+	assert.Equal(t, exitOK, code, stderr)
+}
+
+func TestCheckBaseline(t *testing.T) {
+	newFakePlatform(t)
+	dir := writeFiles(t, map[string]string{"a.txt": "Not bad.", "b.txt": "Good."})
+	a, b := filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt")
+	baseline := filepath.Join(dir, "baseline.json")
+
+	code, _, stderr := runCLI("", "check", "-write-baseline", baseline, a, b)
+	assert.Equal(t, exitOK, code, stderr)
+
+	// The known issue doesn't fail the quality gate.
+	code, stdout, stderr := runCLI("", "check", "-baseline", baseline, "-max-scoring-issues", "required=0", a, b)
+	assert.Equal(t, exitOK, code, stderr)
+	assert.NotContains(t, stdout, "Avoid bad")
+
+	// A new issue does, and the fixed issue is reported.
+	assert.NoError(t, os.WriteFile(a, []byte("Fixed."), 0o644))
+	assert.NoError(t, os.WriteFile(b, []byte("Now bad."), 0o644))
+	code, stdout, stderr = runCLI("", "check", "-baseline", baseline, "-max-scoring-issues", "required=0", a, b)
+	assert.Equal(t, exitQualityGate, code)
+	assert.Contains(t, stdout, b+":1:5: error: Avoid bad")
+	assert.Contains(t, stderr, a+": 1 baseline issues fixed\n")
+
+	// Scores include the baseline issues, so only issue limits apply.
+	assert.NoError(t, os.WriteFile(b, []byte("Good."), 0o644))
+	assert.NoError(t, os.WriteFile(a, []byte("Not bad."), 0o644))
+	code, _, stderr = runCLI("", "check", "-baseline", baseline, "-min-score", "90", a, b)
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stderr, "ignoring score and status thresholds with -baseline")
+
+	code, _, stderr = runCLI("", "check", "-baseline", filepath.Join(dir, "missing.json"), a)
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "Error reading baseline")
+}
+
+func TestCheckDirectives(t *testing.T) {
+	newFakePlatform(t)
+	dir := writeFiles(t, map[string]string{
+		"a.md": "<!-- acrolinx-disable-next-line CLARITY -->\nNot bad.\n<!-- acrolinx-disable-line -->\n",
+	})
+	a := filepath.Join(dir, "a.md")
+
+	code, stdout, stderr := runCLI("", "check", "-report-unused-directives", "-max-scoring-issues", "required=0", a)
+	assert.Equal(t, exitOK, code, stderr)
+	assert.NotContains(t, stdout, "Avoid bad")
+	assert.Equal(t, a+":3: unused acrolinx-disable-line directive\n", stderr)
+
+	code, stdout, _ = runCLI("", "check", "-ignore-directives", a)
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, a+":2:5: error: Avoid bad")
+}
+
+func TestCheckDiff(t *testing.T) {
+	p := newFakePlatform(t)
+	dir := writeFiles(t, map[string]string{
+		"a.md": "Fine.\n\nNot bad. Changed.\n",
+		"b.md": "Unchanged and bad.\n",
+		"c.md": "Changed.\n\nStill bad.\n",
+		"changes.diff": "--- a/a.md\n+++ b/a.md\n@@ -3 +3 @@\n-Not bad.\n+Not bad. Changed.\n" +
+			"--- a/c.md\n+++ b/c.md\n@@ -1 +1 @@\n-Old.\n+Changed.\n",
+	})
+
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(dir))
+	defer os.Chdir(wd)
+
+	code, stdout, stderr := runCLI("", "check", "-diff-file", "changes.diff", "-max-scoring-issues", "required=0", "a.md", "b.md", "c.md")
+	assert.Equal(t, exitQualityGate, code)
+	assert.Contains(t, stdout, "a.md:3:5: error: Avoid bad")
+	assert.Contains(t, stdout, "c.md: no issues")
+	assert.Equal(t, "a.md: 1 required issues exceed the maximum of 0\n", stderr)
+
+	assert.Len(t, p.submitted, 2)
+	assert.Equal(t, []*acrolinx.PartialCheckRange{{Begin: 7, End: 24}}, p.submitted[0].CheckOptions.PartialCheckRanges)
+	assert.Equal(t, []*acrolinx.PartialCheckRange{{Begin: 0, End: 8}}, p.submitted[1].CheckOptions.PartialCheckRanges)
+
+	code, _, stderr = runCLI("", "check", "-diff-file", "changes.diff", "-diff-align", "word", "a.md")
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "unknown -diff-align")
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestWatch(t *testing.T) {
+	p := newFakePlatform(t)
+	dir := writeFiles(t, map[string]string{"docs/a.md": "Fine.\n", "docs/.hidden.md": "bad"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var stdout, stderr syncBuffer
+	done := make(chan int)
+	go func() {
+		done <- run(ctx, []string{"watch", "-interval", "5ms", "-debounce", "10ms", filepath.Join(dir, "docs")}, strings.NewReader(""), &stdout, &stderr)
+	}()
+
+	a := filepath.Join(dir, "docs", "a.md")
+	assert.Eventually(t, func() bool {
+		return strings.Contains(stdout.String(), a+": no issues")
+	}, 5*time.Second, 5*time.Millisecond)
+
+	// Ensure the modification time changes on file systems with coarse
+	// timestamps.
+	assert.NoError(t, os.WriteFile(a, []byte("Fine.\nNow bad.\n"), 0o644))
+	assert.NoError(t, os.Chtimes(a, time.Now().Add(time.Minute), time.Now().Add(time.Minute)))
+	assert.Eventually(t, func() bool {
+		return strings.Contains(stdout.String(), a+":2:5: error: Avoid bad")
+	}, 5*time.Second, 5*time.Millisecond)
+
+	cancel()
+	assert.Equal(t, exitOK, <-done)
+	assert.Contains(t, stderr.String(), "Watching "+filepath.Join(dir, "docs"))
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	assert.Len(t, p.submitted, 2)
+	assert.Equal(t, "a.md", p.submitted[0].Document.Reference)
+	assert.Equal(t, []*acrolinx.PartialCheckRange{{Begin: 6, End: 14}}, p.submitted[1].CheckOptions.PartialCheckRanges)
+}
+
+// lspMessage frames a JSON-RPC message for the language server.
+func lspMessage(t *testing.T, msg map[string]interface{}) string {
+	msg["jsonrpc"] = "2.0"
+	data, err := json.Marshal(msg)
+	assert.NoError(t, err)
+	return fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(data), data)
+}
+
+func TestLSP(t *testing.T) {
+	p := newFakePlatform(t)
+
+	stdin, w := io.Pipe()
+	var stdout, stderr syncBuffer
+	done := make(chan int)
+	go func() {
+		done <- run(context.Background(), []string{"lsp", "-profile", "de-1"}, stdin, &stdout, &stderr)
+	}()
+
+	_, err := io.WriteString(w, lspMessage(t, map[string]interface{}{"id": 1, "method": "initialize", "params": map[string]interface{}{}}))
+	assert.NoError(t, err)
+	_, err = io.WriteString(w, lspMessage(t, map[string]interface{}{
+		"method": "textDocument/didOpen",
+		"params": map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": "file:///a.md", "version": 1, "text": "Not bad.\n"},
+		},
+	}))
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return strings.Contains(stdout.String(), `"method":"textDocument/publishDiagnostics"`)
+	}, 5*time.Second, 5*time.Millisecond)
+	assert.Contains(t, stdout.String(), `"executeCommandProvider":{"commands":["acrolinx.addToDictionary"]}`)
+	assert.Contains(t, stdout.String(), `{"range":{"start":{"line":0,"character":4},"end":{"line":0,"character":7}},"severity":1,"code":"avoid_bad","source":"Acrolinx","message":"Avoid bad"}`)
+
+	_, err = io.WriteString(w, lspMessage(t, map[string]interface{}{"id": 2, "method": "shutdown"}))
+	assert.NoError(t, err)
+	_, err = io.WriteString(w, lspMessage(t, map[string]interface{}{"method": "exit"}))
+	assert.NoError(t, err)
+	assert.Equal(t, exitOK, <-done, stderr.String())
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	assert.Len(t, p.submitted, 1)
+	assert.Equal(t, "de-1", p.submitted[0].CheckOptions.GuidanceProfileID)
+}
+
+func TestLSPExitWithoutShutdown(t *testing.T) {
+	newFakePlatform(t)
+
+	stdin := lspMessage(t, map[string]interface{}{"id": 1, "method": "initialize", "params": map[string]interface{}{}}) +
+		lspMessage(t, map[string]interface{}{"method": "exit"})
+	code, _, stderr := runCLI(stdin, "lsp")
+	assert.Equal(t, exitWithoutShutdown, code)
+	assert.Empty(t, stderr)
+}
+
+func TestCheckOutputFile(t *testing.T) {
+	newFakePlatform(t)
+	dir := writeFiles(t, map[string]string{"a.txt": "bad"})
+	report := filepath.Join(dir, "report.json")
+
+	code, stdout, stderr := runCLI("", "check", "-format", "json", "-output", report, filepath.Join(dir, "a.txt"))
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Empty(t, stdout)
+
+	data, err := os.ReadFile(report)
+	assert.NoError(t, err)
+
+	var docs []*jsonDocument
+	assert.NoError(t, json.Unmarshal(data, &docs))
+	assert.Len(t, docs, 1)
+	assert.Equal(t, 40, docs[0].Result.Quality.Score)
+}
+
+func TestCheckErrors(t *testing.T) {
+	newFakePlatform(t)
+	dir := writeFiles(t, map[string]string{"a.txt": "text"})
+	file := filepath.Join(dir, "a.txt")
+
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"-format", "yaml", file}, `unknown format "yaml"`},
+		{[]string{"-profile", "fr-1", file}, `unknown profile "fr-1"`},
+		{[]string{"-language", "fr", file}, `no profile for language "fr"`},
+		{[]string{"-content-format", "HTML", file}, `unsupported content format "HTML"`},
+		{[]string{filepath.Join(dir, "*.md")}, "no matching files"},
+		{[]string{filepath.Join(dir, "missing.txt")}, "Error reading"},
+		{[]string{"-url", "", file}, "no platform URL"},
+		{[]string{"-signature", "", file}, "no client signature"},
+	}
+
+	for _, test := range tests {
+		code, _, stderr := runCLI("", append([]string{"check"}, test.args...)...)
+		assert.Equal(t, exitError, code, test.args)
+		assert.Contains(t, stderr, test.expected)
+	}
+}
+
+func TestResult(t *testing.T) {
+	p := newFakePlatform(t)
+	dir := writeFiles(t, map[string]string{"a.txt": "Too bad."})
+	file := filepath.Join(dir, "a.txt")
+	p.submitted = []*acrolinx.SubmitCheckOptions{{Content: "Too bad."}}
+
+	code, stdout, stderr := runCLI("", "result", "-file", file, "check-0")
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, file+":1:5: error: Avoid bad")
+
+	// Without the file, issues can't be located.
+	code, stdout, stderr = runCLI("", "result", "check-0")
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, "error: Avoid bad")
+	assert.NotContains(t, stdout, ":1:")
+
+	code, _, _ = runCLI("", "result", "-min-score", "80", "-wait", "check-0")
+	assert.Equal(t, exitQualityGate, code)
+
+	code, _, stderr = runCLI("", "result", "running")
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "check running is still running (30%): Still processing")
+
+	code, _, stderr = runCLI("", "result")
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "expected 1 arguments, got 0")
+}
+
+func TestCancel(t *testing.T) {
+	newFakePlatform(t)
+
+	code, stdout, stderr := runCLI("", "cancel", "check-7")
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "Cancelled check check-7\n", stdout)
+}
+
+func TestExpandPaths(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"b.md": "", "a.md": "", "c.txt": ""})
+
+	paths, err := expandPaths(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"-"}, paths)
+
+	paths, err = expandPaths([]string{filepath.Join(dir, "c.txt"), filepath.Join(dir, "*.md"), filepath.Join(dir, "a.md"), "-"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "c.txt"),
+		filepath.Join(dir, "a.md"),
+		filepath.Join(dir, "b.md"),
+		"-",
+	}, paths)
+
+	paths, err = expandPaths([]string{dir})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{dir}, paths)
+}
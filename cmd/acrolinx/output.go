@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/acrolinx/go-acrolinx"
+)
+
+type reportWriter func(w io.Writer, docs []*acrolinx.CheckedDocument) error
+
+var reportWriters = map[string]reportWriter{
+	"json":       writeJSON,
+	"sarif":      acrolinx.WriteSARIF,
+	"junit":      acrolinx.WriteJUnit,
+	"checkstyle": acrolinx.WriteCheckstyle,
+	"github":     acrolinx.WriteGitHubActions,
+	"gitlab":     acrolinx.WriteGitLabCodeQuality,
+	"rdjson":     acrolinx.WriteRDJSON,
+	"rdjsonl":    acrolinx.WriteRDJSONL,
+	"html":       acrolinx.WriteHTML,
+	"markdown":   acrolinx.WriteMarkdown,
+	"csv":        acrolinx.WriteIssuesCSV,
+	"ndjson":     acrolinx.WriteIssuesNDJSON,
+}
+
+func formatNames() []string {
+	names := []string{"text"}
+	for name := range reportWriters {
+		names = append(names, name)
+	}
+	sort.Strings(names[1:])
+
+	return names
+}
+
+// output holds the flags selecting the report format and destination.
+type output struct {
+	format string
+	path   string
+}
+
+func (o *output) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.format, "format", "text", "report `format`: "+strings.Join(formatNames(), ", "))
+	fs.StringVar(&o.path, "output", "", "write the report to `file` instead of stdout")
+}
+
+func (o *output) validate() error {
+	if _, ok := reportWriters[o.format]; !ok && o.format != "text" {
+		return fmt.Errorf("Error parsing arguments: unknown format %q, expected one of %s", o.format, strings.Join(formatNames(), ", "))
+	}
+
+	return nil
+}
+
+// write writes the report for docs to the output file, or else to
+// stdout.
+func (o *output) write(c *cli, docs []*acrolinx.CheckedDocument) error {
+	if o.path == "" {
+		return o.writeTo(c.stdout, docs)
+	}
+
+	f, err := os.Create(o.path)
+	if err != nil {
+		return fmt.Errorf("Error creating report: %w", err)
+	}
+
+	if err := o.writeTo(f, docs); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("Error writing report: %w", err)
+	}
+
+	return nil
+}
+
+// writeTo writes the report for docs to w. Text reports written to a
+// terminal are colored.
+func (o *output) writeTo(w io.Writer, docs []*acrolinx.CheckedDocument) error {
+	if o.format == "text" {
+		f, ok := w.(*os.File)
+		return acrolinx.WriteText(w, docs, &acrolinx.TextOptions{Color: ok && acrolinx.ColorSupported(f)})
+	}
+
+	return reportWriters[o.format](w, docs)
+}
+
+type jsonDocument struct {
+	Path   string                `json:"path"`
+	Result *acrolinx.CheckResult `json:"result"`
+}
+
+// writeJSON writes the raw check results of docs.
+func writeJSON(w io.Writer, docs []*acrolinx.CheckedDocument) error {
+	out := make([]*jsonDocument, len(docs))
+	for i, doc := range docs {
+		out[i] = &jsonDocument{doc.Path, doc.Result}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		return fmt.Errorf("Error writing JSON: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/acrolinx/go-acrolinx"
+)
+
+// policyFlags holds the flags defining the quality gate. Thresholds
+// given as flags take precedence over those in the policy file.
+type policyFlags struct {
+	fs     *flag.FlagSet
+	file   string
+	policy acrolinx.QualityPolicy
+}
+
+func (pf *policyFlags) register(fs *flag.FlagSet) {
+	pf.fs = fs
+	fs.StringVar(&pf.file, "policy", "", "read the quality policy from a JSON `file`")
+	fs.IntVar(&pf.policy.MinScore, "min-score", 0, "fail if a document scores below `score`")
+	fs.Var(&limitsFlag{&pf.policy.MinGoalScores}, "min-goal-score", "fail if a goal scores below a minimum, given as `GOAL=score` (repeatable)")
+	fs.Var(&limitsFlag{&pf.policy.MaxGoalIssues}, "max-goal-issues", "fail if a goal has more issues, given as `GOAL=count` (repeatable)")
+	fs.Var(&limitsFlag{&pf.policy.MaxScoringIssues}, "max-scoring-issues", "fail if a scoring level has more issues, given as `LEVEL=count` (repeatable)")
+	fs.Var(&listFlag{&pf.policy.AllowedStatuses}, "allowed-status", "fail unless the status is one of the comma-separated `statuses`")
+}
+
+// load returns the policy from the policy file, overridden by the
+// thresholds given as flags.
+func (pf *policyFlags) load() (*acrolinx.QualityPolicy, error) {
+	policy := &acrolinx.QualityPolicy{}
+	if pf.file != "" {
+		data, err := os.ReadFile(pf.file)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading policy: %w", err)
+		}
+
+		if err := json.Unmarshal(data, policy); err != nil {
+			return nil, fmt.Errorf("Error parsing policy %s: %w", pf.file, err)
+		}
+	}
+
+	// Flags set explicitly override the file even if they are zero or
+	// empty.
+	set := make(map[string]bool)
+	if pf.fs != nil {
+		pf.fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	}
+
+	if set["min-score"] {
+		policy.MinScore = pf.policy.MinScore
+	}
+	policy.MinGoalScores = mergeLimits(policy.MinGoalScores, pf.policy.MinGoalScores)
+	policy.MaxGoalIssues = mergeLimits(policy.MaxGoalIssues, pf.policy.MaxGoalIssues)
+	policy.MaxScoringIssues = mergeLimits(policy.MaxScoringIssues, pf.policy.MaxScoringIssues)
+	if set["allowed-status"] {
+		policy.AllowedStatuses = pf.policy.AllowedStatuses
+	}
+
+	return policy, nil
+}
+
+func mergeLimits(base, overrides map[string]int) map[string]int {
+	if len(overrides) == 0 {
+		return base
+	}
+
+	if base == nil {
+		base = make(map[string]int)
+	}
+	for k, v := range overrides {
+		base[k] = v
+	}
+
+	return base
+}
+
+// checkPolicy prints the violations of every document failing policy
+// and returns errQualityGate if there is any.
+func (c *cli) checkPolicy(docs []*acrolinx.CheckedDocument, policy *acrolinx.QualityPolicy) error {
+	batch := policy.EvaluateBatch(docs)
+	for _, d := range batch.Failed() {
+		for _, v := range d.Violations {
+			fmt.Fprintf(c.stderr, "%s: %s\n", d.Document.Path, v.Message)
+		}
+	}
+
+	if !batch.Passed {
+		return errQualityGate
+	}
+
+	return nil
+}
+
+// limitsFlag parses repeated KEY=N flags into a map.
+type limitsFlag struct {
+	m *map[string]int
+}
+
+func (f *limitsFlag) String() string {
+	if f.m == nil || *f.m == nil {
+		return ""
+	}
+
+	var pairs []string
+	for k, v := range *f.m {
+		pairs = append(pairs, fmt.Sprintf("%s=%d", k, v))
+	}
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ",")
+}
+
+func (f *limitsFlag) Set(s string) error {
+	key, value, ok := strings.Cut(s, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("expected KEY=N, got %q", s)
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("expected KEY=N, got %q", s)
+	}
+
+	if *f.m == nil {
+		*f.m = make(map[string]int)
+	}
+	(*f.m)[key] = n
+
+	return nil
+}
+
+// listFlag parses a comma-separated list.
+type listFlag struct {
+	list *[]string
+}
+
+func (f *listFlag) String() string {
+	if f.list == nil {
+		return ""
+	}
+
+	return strings.Join(*f.list, ",")
+}
+
+func (f *listFlag) Set(s string) error {
+	*f.list = nil
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*f.list = append(*f.list, item)
+		}
+	}
+
+	return nil
+}
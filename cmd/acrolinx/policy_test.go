@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/acrolinx/go-acrolinx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicyFlags(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "policy.json")
+	assert.NoError(t, os.WriteFile(file, []byte(`{
+		"minScore": 60,
+		"minGoalScores": {"CLARITY": 50},
+		"maxScoringIssues": {"required": 0},
+		"allowedStatuses": ["green"]
+	}`), 0o644))
+
+	var gate policyFlags
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	gate.register(fs)
+
+	assert.NoError(t, fs.Parse([]string{
+		"-policy", file,
+		"-min-score", "70",
+		"-min-goal-score", "CONSISTENCY=80",
+		"-min-goal-score", "CLARITY=55",
+		"-max-goal-issues", "SPELLING=2",
+		"-allowed-status", "green, yellow",
+	}))
+
+	policy, err := gate.load()
+	assert.NoError(t, err)
+	assert.Equal(t, &acrolinx.QualityPolicy{
+		MinScore:         70,
+		MinGoalScores:    map[string]int{"CLARITY": 55, "CONSISTENCY": 80},
+		MaxGoalIssues:    map[string]int{"SPELLING": 2},
+		MaxScoringIssues: map[string]int{"required": 0},
+		AllowedStatuses:  []string{"green", "yellow"},
+	}, policy)
+
+	assert.Error(t, fs.Parse([]string{"-max-goal-issues", "SPELLING"}))
+	assert.Error(t, fs.Parse([]string{"-max-goal-issues", "SPELLING=many"}))
+}
+
+func TestPolicyFlagsOverrideWithZero(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "policy.json")
+	assert.NoError(t, os.WriteFile(file, []byte(`{"minScore": 60, "allowedStatuses": ["green"]}`), 0o644))
+
+	var gate policyFlags
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	gate.register(fs)
+
+	assert.NoError(t, fs.Parse([]string{"-policy", file, "-min-score", "0"}))
+	policy, err := gate.load()
+	assert.NoError(t, err)
+	assert.Equal(t, &acrolinx.QualityPolicy{AllowedStatuses: []string{"green"}}, policy)
+}
+
+func TestPolicyFile(t *testing.T) {
+	gate := policyFlags{file: filepath.Join(t.TempDir(), "missing.json")}
+	_, err := gate.load()
+	assert.ErrorContains(t, err, "Error reading policy")
+
+	gate.file = filepath.Join(t.TempDir(), "policy.json")
+	assert.NoError(t, os.WriteFile(gate.file, []byte("{"), 0o644))
+	_, err = gate.load()
+	assert.ErrorContains(t, err, "Error parsing policy")
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/acrolinx/go-acrolinx"
+)
+
+func (c *cli) watch(args []string) error {
+	fs := c.flagSet("watch", "[flags] [dir]")
+	var conn connection
+	conn.register(fs)
+	profile := fs.String("profile", "", "guidance profile `ID or name`, defaults to the platform default")
+	language := fs.String("language", "", "use the guidance profile for the language `ID`")
+	contentFormat := fs.String("content-format", "", "content `format`, detected from the file name by default")
+	checkType := fs.String("check-type", "", "check `type`, defaults to the platform default")
+	var include, exclude []string
+	fs.Var(&listFlag{&include}, "include", "only watch files matching the comma-separated `patterns`")
+	fs.Var(&listFlag{&exclude}, "exclude", "skip files and directories matching the comma-separated `patterns`")
+	interval := fs.Duration("interval", 500*time.Millisecond, "`duration` between polls for changes")
+	debounce := fs.Duration("debounce", 300*time.Millisecond, "`duration` a file must stay unchanged before it is checked")
+	align := fs.String("diff-align", "sentence", "extend changed lines to the enclosing `unit`: sentence, paragraph or line")
+	initial := fs.Bool("initial", true, "check all files when starting")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return fmt.Errorf("Error parsing arguments: expected at most one directory, got %d arguments", fs.NArg())
+	}
+
+	alignment, ok := alignments[*align]
+	if !ok {
+		return fmt.Errorf("Error: unknown -diff-align %q", *align)
+	}
+
+	dir := "."
+	if fs.NArg() == 1 {
+		dir = fs.Arg(0)
+	}
+
+	client, err := conn.client()
+	if err != nil {
+		return err
+	}
+
+	caps, _, err := client.Checking.GetCapabilities(nil)
+	if err != nil {
+		return fmt.Errorf("Error getting capabilities: %w", err)
+	}
+
+	profileID, err := selectProfile(caps, *profile, *language)
+	if err != nil {
+		return err
+	}
+
+	resolver, err := acrolinx.NewContentFormatResolver(caps)
+	if err != nil {
+		return err
+	}
+
+	results, err := acrolinx.Watch(c.ctx, client.Checking, os.DirFS(dir), &acrolinx.WatchOptions{
+		Walk: &acrolinx.WalkOptions{
+			Include: include,
+			Exclude: append([]string{".*"}, exclude...),
+			CheckOptions: &acrolinx.CheckOptions{
+				GuidanceProfileID: profileID,
+				ContentFormat:     *contentFormat,
+				CheckType:         *checkType,
+			},
+			Formats: resolver,
+		},
+		Interval:     *interval,
+		Debounce:     *debounce,
+		Align:        alignment,
+		CheckInitial: *initial,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stderr, "Watching %s for changes, press Ctrl+C to stop\n", dir)
+
+	text := output{format: "text"}
+	for r := range results {
+		if r.Err != nil {
+			fmt.Fprintf(c.stderr, "acrolinx: %v\n", r.Err)
+			continue
+		}
+
+		r.Document.Path = filepath.Join(dir, filepath.FromSlash(r.Document.Path))
+		if err := text.writeTo(c.stdout, []*acrolinx.CheckedDocument{r.Document}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package acrolinx
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+const (
+	ContentEncodingNone   = "none"
+	ContentEncodingBase64 = "base64"
+)
+
+// SetBinaryContent sets data as the content to check, encoded as
+// base64. Use it for binary documents like DOCX or PPTX files.
+func (o *SubmitCheckOptions) SetBinaryContent(data []byte) {
+	o.Content = base64.StdEncoding.EncodeToString(data)
+	o.ContentEncoding = ContentEncodingBase64
+}
+
+// ErrBinaryContent is returned for binary content in a text format,
+// like an image, which can't be checked.
+var ErrBinaryContent = errors.New("binary content in a text format")
+
+// binaryContentFormats lists the formats of binary documents, which are
+// sent base64 encoded.
+var binaryContentFormats = map[string]bool{
+	ContentFormatMSOffice: true,
+}
+
+// SetFileContent sets the content of a file as the content to check,
+// encoded as base64 if its content format is binary or detected by the
+// platform. The content format must be set first. It returns
+// ErrBinaryContent for other binary content.
+func (o *SubmitCheckOptions) SetFileContent(content []byte) error {
+	var format string
+	if o.CheckOptions != nil {
+		format = o.CheckOptions.ContentFormat
+	}
+
+	switch {
+	case binaryContentFormats[format]:
+		o.SetBinaryContent(content)
+	case !isBinary(content):
+		o.Content = string(content)
+		o.ContentEncoding = ""
+	case format == ContentFormatAuto:
+		o.SetBinaryContent(content)
+	default:
+		return ErrBinaryContent
+	}
+
+	return nil
+}
+
+// isBinary reports whether content can't be sent as text, because it
+// isn't valid UTF-8 or contains a NUL byte like most binary files.
+func isBinary(content []byte) bool {
+	return bytes.IndexByte(content, 0) >= 0 || !utf8.Valid(content)
+}
+
+// ReadBinaryContent reads the content to check from r and sets it
+// like SetBinaryContent.
+func (o *SubmitCheckOptions) ReadBinaryContent(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("Error reading content: %w", err)
+	}
+
+	o.SetBinaryContent(data)
+	return nil
+}
+
+// BinaryContent returns the decoded content of o, regardless of its
+// content encoding.
+func (o *SubmitCheckOptions) BinaryContent() ([]byte, error) {
+	switch o.ContentEncoding {
+	case "", ContentEncodingNone:
+		return []byte(o.Content), nil
+	case ContentEncodingBase64:
+		data, err := base64.StdEncoding.DecodeString(o.Content)
+		if err != nil {
+			return nil, fmt.Errorf("Error decoding base64 content: %w", err)
+		}
+		return data, nil
+	}
+
+	return nil, fmt.Errorf("Error decoding content: unknown content encoding %q", o.ContentEncoding)
+}
+
+// SubmitBinaryCheck reads a binary document from r and submits it for
+// checking, after making sure the platform accepts base64 encoded
+// content in the requested format.
+func (s *CheckingService) SubmitBinaryCheck(r io.Reader, opts *SubmitCheckOptions, caps *Capabilities) (*Check, Links, error) {
+	binaryOpts := *opts
+	if err := binaryOpts.ReadBinaryContent(r); err != nil {
+		return nil, nil, err
+	}
+
+	if err := ValidateSubmitCheckOptions(&binaryOpts, caps); err != nil {
+		return nil, nil, err
+	}
+
+	return s.SubmitCheck(&binaryOpts)
+}
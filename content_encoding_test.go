@@ -0,0 +1,118 @@
+package acrolinx
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetBinaryContent(t *testing.T) {
+	opts := &SubmitCheckOptions{}
+	opts.SetBinaryContent([]byte("PK\x03\x04\x00\xff"))
+
+	assert.Equal(t, "UEsDBAD/", opts.Content)
+	assert.Equal(t, "base64", opts.ContentEncoding)
+
+	data, err := opts.BinaryContent()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("PK\x03\x04\x00\xff"), data)
+}
+
+func TestSetFileContent(t *testing.T) {
+	tests := []struct {
+		format   string
+		content  string
+		encoding string
+		err      error
+	}{
+		{ContentFormatMarkdown, "# Title\n", "", nil},
+		{ContentFormatMSOffice, "PK\x03\x04\x00\xff", ContentEncodingBase64, nil},
+		{ContentFormatAuto, "PK\x03\x04\x00\xff", ContentEncodingBase64, nil},
+		{ContentFormatAuto, "text", "", nil},
+		{ContentFormatText, "\x89PNG\r\n", "", ErrBinaryContent},
+		{ContentFormatText, "GIF89a\x00\x01", "", ErrBinaryContent},
+	}
+
+	for _, tt := range tests {
+		opts := &SubmitCheckOptions{CheckOptions: &CheckOptions{ContentFormat: tt.format}}
+		err := opts.SetFileContent([]byte(tt.content))
+		assert.ErrorIs(t, err, tt.err, tt.format)
+		if err != nil {
+			continue
+		}
+		assert.Equal(t, tt.encoding, opts.ContentEncoding, tt.format)
+
+		data, err := opts.BinaryContent()
+		assert.NoError(t, err)
+		assert.Equal(t, tt.content, string(data))
+	}
+}
+
+func TestBinaryContent(t *testing.T) {
+	data, err := (&SubmitCheckOptions{Content: "text"}).BinaryContent()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("text"), data)
+
+	_, err = (&SubmitCheckOptions{Content: "!!", ContentEncoding: "base64"}).BinaryContent()
+	assert.ErrorContains(t, err, "Error decoding base64 content")
+
+	_, err = (&SubmitCheckOptions{Content: "text", ContentEncoding: "rot13"}).BinaryContent()
+	assert.ErrorContains(t, err, "unknown content encoding")
+}
+
+func TestSubmitBinaryCheck(t *testing.T) {
+	mux, server, client := setup(t)
+	defer teardown(server)
+
+	mux.HandleFunc("/api/v1/checking/checks", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodPost)
+
+		var opts SubmitCheckOptions
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&opts))
+		assert.Equal(t, "base64", opts.ContentEncoding)
+		assert.Equal(t, "UEsDBA==", opts.Content)
+		assert.Equal(t, "MS_OFFICE", opts.CheckOptions.ContentFormat)
+
+		mustWriteHTTPResponse(t, w, "submit_check.json")
+	})
+
+	opts := &SubmitCheckOptions{
+		CheckOptions: &CheckOptions{ContentFormat: ContentFormatMSOffice},
+	}
+	check, _, err := client.Checking.SubmitBinaryCheck(bytes.NewReader([]byte("PK\x03\x04")), opts, testCapabilities())
+	assert.NoError(t, err)
+	assert.Equal(t, "052929ee-be0c-46a7-87ce-eebd308fef6e", check.ID)
+	assert.Empty(t, opts.Content)
+}
+
+func TestSubmitBinaryCheckUnsupported(t *testing.T) {
+	_, server, client := setup(t)
+	defer teardown(server)
+
+	caps := testCapabilities()
+	caps.ContentEncodings = []string{"none"}
+
+	opts := &SubmitCheckOptions{
+		CheckOptions: &CheckOptions{ContentFormat: ContentFormatMSOffice},
+	}
+	_, _, err := client.Checking.SubmitBinaryCheck(bytes.NewReader([]byte("PK\x03\x04")), opts, caps)
+
+	var validationErr *ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, []*FieldError{{"contentEncoding", `unsupported content encoding "base64"`}}, validationErr.Errors)
+}
+
+func TestSubmitBinaryCheckWithoutCapabilities(t *testing.T) {
+	_, server, client := setup(t)
+	defer teardown(server)
+
+	opts := &SubmitCheckOptions{
+		CheckOptions: &CheckOptions{ContentFormat: ContentFormatMSOffice},
+	}
+	_, _, err := client.Checking.SubmitBinaryCheck(bytes.NewReader([]byte("PK\x03\x04")), opts, nil)
+	assert.EqualError(t, err, "Invalid check options: missing capabilities")
+}
@@ -0,0 +1,231 @@
+package acrolinx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	ContentFormatAuto     = "AUTO"
+	ContentFormatText     = "TEXT"
+	ContentFormatMarkdown = "MARKDOWN"
+	ContentFormatHTML     = "HTML"
+	ContentFormatXML      = "XML"
+	ContentFormatDITA     = "DITA"
+	ContentFormatJSON     = "JSON"
+	ContentFormatYAML     = "YAML"
+	ContentFormatMSOffice = "MS_OFFICE"
+)
+
+var defaultContentFormats = map[string]string{
+	".md":       ContentFormatMarkdown,
+	".markdown": ContentFormatMarkdown,
+	".mdown":    ContentFormatMarkdown,
+	".mkd":      ContentFormatMarkdown,
+	".html":     ContentFormatHTML,
+	".htm":      ContentFormatHTML,
+	".xhtml":    ContentFormatHTML,
+	".xml":      ContentFormatXML,
+	".svg":      ContentFormatXML,
+	".resx":     ContentFormatXML,
+	".dita":     ContentFormatDITA,
+	".ditamap":  ContentFormatDITA,
+	".json":     ContentFormatJSON,
+	".yaml":     ContentFormatYAML,
+	".yml":      ContentFormatYAML,
+	".txt":      ContentFormatText,
+	".text":     ContentFormatText,
+	".docx":     ContentFormatMSOffice,
+	".pptx":     ContentFormatMSOffice,
+	".xlsx":     ContentFormatMSOffice,
+}
+
+// contentFormatFallbacks lists the format to use if the platform does
+// not support a format. Anything not listed falls back to plain text.
+var contentFormatFallbacks = map[string]string{
+	ContentFormatDITA: ContentFormatXML,
+}
+
+// ResolvedFormat is the outcome of resolving the content format of a
+// file.
+type ResolvedFormat struct {
+	ContentFormat string
+
+	// UseReference is set if the platform detects the format from the
+	// document reference itself. The file path must then be sent as
+	// Document.Reference, and ContentFormat is ContentFormatAuto.
+	UseReference bool
+}
+
+// ContentFormatResolver picks the content format for files based on
+// their names, their content and the platform capabilities.
+type ContentFormatResolver struct {
+	caps             *Capabilities
+	referencePattern *regexp.Regexp
+	overrides        map[string]string
+}
+
+// NewContentFormatResolver creates a resolver for the given
+// capabilities. caps may be nil, in which case formats are resolved
+// without checking for platform support.
+func NewContentFormatResolver(caps *Capabilities) (*ContentFormatResolver, error) {
+	r := &ContentFormatResolver{
+		caps:      caps,
+		overrides: make(map[string]string),
+	}
+
+	if caps != nil && caps.ReferencePattern != "" {
+		pattern, err := regexp.Compile(caps.ReferencePattern)
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing reference pattern: %w", err)
+		}
+		r.referencePattern = pattern
+	}
+
+	return r, nil
+}
+
+// SetFormat maps files with the extension ext to format, taking
+// precedence over both the built-in mappings and the platform's
+// reference pattern.
+func (r *ContentFormatResolver) SetFormat(ext string, format string) {
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	r.overrides[strings.ToLower(ext)] = format
+}
+
+// Resolve determines the content format of the file at filePath.
+// content may be nil; if given, it is used to detect the format of
+// files with unknown extensions.
+func (r *ContentFormatResolver) Resolve(filePath string, content []byte) *ResolvedFormat {
+	ext := strings.ToLower(filepath.Ext(filePath))
+
+	if format, ok := r.overrides[ext]; ok {
+		return &ResolvedFormat{ContentFormat: format}
+	}
+
+	if r.referencePattern != nil && r.referencePattern.MatchString(filePath) {
+		return &ResolvedFormat{ContentFormat: ContentFormatAuto, UseReference: true}
+	}
+
+	format, ok := defaultContentFormats[ext]
+	if !ok {
+		format = sniffContentFormat(content)
+	}
+
+	return &ResolvedFormat{ContentFormat: r.supported(format)}
+}
+
+// Apply resolves the content format of filePath and sets it on opts,
+// along with the document reference if the platform needs it.
+func (r *ContentFormatResolver) Apply(opts *SubmitCheckOptions, filePath string, content []byte) {
+	resolved := r.Resolve(filePath, content)
+
+	if opts.CheckOptions == nil {
+		opts.CheckOptions = &CheckOptions{}
+	}
+	opts.CheckOptions.ContentFormat = resolved.ContentFormat
+
+	if resolved.UseReference {
+		if opts.Document == nil {
+			opts.Document = &Document{}
+		}
+		opts.Document.Reference = filePath
+	}
+}
+
+func (r *ContentFormatResolver) supported(format string) string {
+	if r.caps == nil {
+		return format
+	}
+
+	for format != ContentFormatText && !r.caps.SupportsContentFormat(format) {
+		fallback, ok := contentFormatFallbacks[format]
+		if !ok {
+			fallback = ContentFormatText
+		}
+		format = fallback
+	}
+
+	return format
+}
+
+func sniffContentFormat(content []byte) string {
+	content = bytes.TrimLeft(content, "\ufeff \t\r\n")
+
+	switch {
+	case len(content) == 0:
+		return ContentFormatText
+	case bytes.HasPrefix(content, []byte("PK\x03\x04")):
+		return ContentFormatMSOffice
+	case content[0] == '<':
+		return sniffMarkupFormat(content)
+	case (content[0] == '{' || content[0] == '[') && json.Valid(content):
+		return ContentFormatJSON
+	}
+
+	return ContentFormatText
+}
+
+func sniffMarkupFormat(content []byte) string {
+	head := content
+	if len(head) > 1024 {
+		head = head[:1024]
+	}
+	head = bytes.ToLower(head)
+
+	switch {
+	case bytes.Contains(head, []byte("<!doctype html")) || bytes.Contains(head, []byte("<html")):
+		return ContentFormatHTML
+	case bytes.Contains(head, []byte("-//oasis//dtd dita")) || ditaRootElements[markupRootElement(head)]:
+		return ContentFormatDITA
+	}
+
+	return ContentFormatXML
+}
+
+// ditaRootElements are the root elements of DITA topics and maps.
+var ditaRootElements = map[string]bool{
+	"topic":     true,
+	"concept":   true,
+	"task":      true,
+	"reference": true,
+	"map":       true,
+	"bookmap":   true,
+}
+
+var markupElement = regexp.MustCompile(`<([a-z][a-z0-9_.:-]*)`)
+
+// markupRootElement returns the name of the first element of head,
+// skipping the XML declaration, comments and the document type
+// declaration, or "" if there is none.
+func markupRootElement(head []byte) string {
+	for {
+		switch {
+		case bytes.HasPrefix(head, []byte("<!--")):
+			end := bytes.Index(head, []byte("-->"))
+			if end < 0 {
+				return ""
+			}
+			head = head[end+3:]
+		case bytes.HasPrefix(head, []byte("<?")) || bytes.HasPrefix(head, []byte("<!")):
+			end := bytes.IndexByte(head, '>')
+			if end < 0 {
+				return ""
+			}
+			head = head[end+1:]
+		default:
+			m := markupElement.FindSubmatch(head)
+			if m == nil || bytes.IndexByte(head, '<') != bytes.Index(head, m[0]) {
+				return ""
+			}
+			return string(m[1])
+		}
+		head = bytes.TrimLeft(head, " \t\r\n")
+	}
+}
@@ -0,0 +1,103 @@
+package acrolinx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContentFormatResolver(t *testing.T) {
+	r, err := NewContentFormatResolver(testCapabilities())
+	assert.NoError(t, err)
+
+	tests := []struct {
+		path     string
+		content  string
+		expected *ResolvedFormat
+	}{
+		{"docs/README.md", "", &ResolvedFormat{ContentFormat: "MARKDOWN"}},
+		{"docs/GUIDE.MARKDOWN", "", &ResolvedFormat{ContentFormat: "MARKDOWN"}},
+		{"docs/notes.txt", "", &ResolvedFormat{ContentFormat: "TEXT"}},
+		{"docs/topic.xml", "", &ResolvedFormat{ContentFormat: "AUTO", UseReference: true}},
+		{"docs/page.XHTML", "", &ResolvedFormat{ContentFormat: "AUTO", UseReference: true}},
+		{"docs/topic.dita", "", &ResolvedFormat{ContentFormat: "XML"}},
+		{"docs/manual.docx", "", &ResolvedFormat{ContentFormat: "MS_OFFICE"}},
+		{"docs/index.html", "", &ResolvedFormat{ContentFormat: "TEXT"}},
+		{"docs/config.yaml", "", &ResolvedFormat{ContentFormat: "TEXT"}},
+		{"docs/LICENSE", "Licensed under the Apache License", &ResolvedFormat{ContentFormat: "TEXT"}},
+		{"docs/data", `<?xml version="1.0"?><root/>`, &ResolvedFormat{ContentFormat: "XML"}},
+		{"docs/topic", `<!DOCTYPE concept PUBLIC "-//OASIS//DTD DITA Concept//EN" "concept.dtd">`, &ResolvedFormat{ContentFormat: "XML"}},
+		{"docs/archive", "PK\x03\x04rest", &ResolvedFormat{ContentFormat: "MS_OFFICE"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			assert.Equal(t, test.expected, r.Resolve(test.path, []byte(test.content)))
+		})
+	}
+}
+
+func TestContentFormatResolverWithoutCapabilities(t *testing.T) {
+	r, err := NewContentFormatResolver(nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "DITA", r.Resolve("topic.dita", nil).ContentFormat)
+	assert.Equal(t, "HTML", r.Resolve("index.htm", nil).ContentFormat)
+	assert.Equal(t, "XML", r.Resolve("topic.xml", nil).ContentFormat)
+	assert.Equal(t, "YAML", r.Resolve("config.yml", nil).ContentFormat)
+	assert.Equal(t, "JSON", r.Resolve("data", []byte(` {"key": "value"}`)).ContentFormat)
+	assert.Equal(t, "HTML", r.Resolve("page", []byte("<!doctype html><html></html>")).ContentFormat)
+	assert.Equal(t, "DITA", r.Resolve("topic", []byte(`<!DOCTYPE task PUBLIC "-//OASIS//DTD DITA Task//EN">`)).ContentFormat)
+	assert.Equal(t, "TEXT", r.Resolve("empty", nil).ContentFormat)
+}
+
+func TestSniffMarkupFormat(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected string
+	}{
+		{`<!DOCTYPE concept PUBLIC "-//OASIS//DTD DITA Concept//EN" "concept.dtd">`, ContentFormatDITA},
+		{`<?xml version="1.0"?>` + "\n<!-- A task -->\n<task id=\"install\"><title>Install</title></task>", ContentFormatDITA},
+		{`<map><topicref href="a.dita"/></map>`, ContentFormatDITA},
+		{`<?xml version="1.0"?><form editable="true"/>`, ContentFormatXML},
+		{`<cases><case>extradita</case></cases>`, ContentFormatXML},
+		{`<settings format="dita"/>`, ContentFormatXML},
+		{`<topics><topic/></topics>`, ContentFormatXML},
+		{`<!-- unterminated`, ContentFormatXML},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, sniffContentFormat([]byte(test.content)), test.content)
+	}
+}
+
+func TestContentFormatResolverOverrides(t *testing.T) {
+	r, err := NewContentFormatResolver(testCapabilities())
+	assert.NoError(t, err)
+
+	r.SetFormat("XML", "TEXT")
+	r.SetFormat(".mdx", "MARKDOWN")
+
+	assert.Equal(t, &ResolvedFormat{ContentFormat: "TEXT"}, r.Resolve("topic.xml", nil))
+	assert.Equal(t, &ResolvedFormat{ContentFormat: "MARKDOWN"}, r.Resolve("page.mdx", nil))
+}
+
+func TestContentFormatResolverWithInvalidPattern(t *testing.T) {
+	_, err := NewContentFormatResolver(&Capabilities{ReferencePattern: "(unclosed"})
+	assert.ErrorContains(t, err, "Error parsing reference pattern")
+}
+
+func TestContentFormatResolverApply(t *testing.T) {
+	r, err := NewContentFormatResolver(testCapabilities())
+	assert.NoError(t, err)
+
+	opts := &SubmitCheckOptions{}
+	r.Apply(opts, "docs/topic.xml", nil)
+	assert.Equal(t, "AUTO", opts.CheckOptions.ContentFormat)
+	assert.Equal(t, "docs/topic.xml", opts.Document.Reference)
+
+	opts = &SubmitCheckOptions{}
+	r.Apply(opts, "docs/README.md", nil)
+	assert.Equal(t, "MARKDOWN", opts.CheckOptions.ContentFormat)
+	assert.Nil(t, opts.Document)
+}
@@ -0,0 +1,88 @@
+package acrolinx
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+type DictionaryService struct {
+	client *Client
+}
+
+// Dictionary adds words to the dictionaries of the platform, so that
+// they are no longer reported as spelling issues.
+type Dictionary interface {
+	AddToDictionary(ctx context.Context, opts *AddToDictionaryOptions) (*DictionaryEntry, Links, error)
+}
+
+type DictionaryCapabilities struct {
+	// Scopes lists the dictionaries words can be added to, like
+	// "language", "guidanceProfile" or "document".
+	Scopes []string `json:"scopes"`
+}
+
+type AddToDictionaryOptions struct {
+	Surface string `json:"surface"`
+	// Scope selects the dictionary, one of the scopes of the
+	// dictionary capabilities or CheckResult.DictionaryScopes.
+	Scope             string `json:"scope,omitempty"`
+	Language          string `json:"language,omitempty"`
+	GuidanceProfileID string `json:"guidanceProfileId,omitempty"`
+	DocumentID        string `json:"documentId,omitempty"`
+}
+
+type DictionaryEntry struct {
+	Surface           string `json:"surface"`
+	Scope             string `json:"scope"`
+	Language          string `json:"language"`
+	GuidanceProfileID string `json:"guidanceProfileId"`
+	DocumentID        string `json:"documentId"`
+}
+
+func (s *DictionaryService) GetCapabilities() (*DictionaryCapabilities, Links, error) {
+	req, err := s.client.newRequest(http.MethodGet, "api/v1/dictionary/capabilities", nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error preparing dictionary request: %w", err)
+	}
+
+	var caps DictionaryCapabilities
+	links := make(Links)
+	var reqError RequestError
+	resp := Response{Data: &caps, Links: links, Error: &reqError}
+	err = s.client.do(req, &resp)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error processing dictionary request: %w", err)
+	}
+
+	if reqError != (RequestError{}) {
+		return nil, nil, &reqError
+	}
+
+	return &caps, links, nil
+}
+
+// AddToDictionary adds a word to a dictionary of the platform. The
+// request is aborted when ctx is done.
+func (s *DictionaryService) AddToDictionary(ctx context.Context, opts *AddToDictionaryOptions) (*DictionaryEntry, Links, error) {
+	req, err := s.client.newRequest(http.MethodPost, "api/v1/dictionary/submit", opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error preparing dictionary request: %w", err)
+	}
+	req = req.WithContext(ctx)
+
+	var entry DictionaryEntry
+	links := make(Links)
+	var reqError RequestError
+	resp := Response{Data: &entry, Links: links, Error: &reqError}
+	err = s.client.do(req, &resp)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error processing dictionary request: %w", err)
+	}
+
+	if reqError != (RequestError{}) {
+		return nil, nil, &reqError
+	}
+
+	return &entry, links, nil
+}
@@ -0,0 +1,74 @@
+package acrolinx
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDictionaryCapabilities(t *testing.T) {
+	mux, server, client := setup(t)
+	defer teardown(server)
+
+	mux.HandleFunc("/api/v1/dictionary/capabilities", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodGet)
+		mustWriteHTTPResponse(t, w, "dictionary_capabilities.json")
+	})
+
+	caps, links, err := client.Dictionary.GetCapabilities()
+	assert.NoError(t, err)
+	assert.Equal(t, &DictionaryCapabilities{Scopes: []string{"language", "guidanceProfile", "document"}}, caps)
+	assert.Equal(t, Links{"submit": "https://example.com/api/v1/dictionary/submit"}, links)
+}
+
+func TestAddToDictionary(t *testing.T) {
+	mux, server, client := setup(t)
+	defer teardown(server)
+
+	mux.HandleFunc("/api/v1/dictionary/submit", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodPost)
+
+		var opts AddToDictionaryOptions
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&opts))
+		assert.Equal(t, AddToDictionaryOptions{Surface: "Acrolinx", Scope: "guidanceProfile", GuidanceProfileID: "en-1"}, opts)
+
+		mustWriteHTTPResponse(t, w, "add_to_dictionary.json")
+	})
+
+	entry, _, err := client.Dictionary.AddToDictionary(context.Background(), &AddToDictionaryOptions{
+		Surface:           "Acrolinx",
+		Scope:             "guidanceProfile",
+		GuidanceProfileID: "en-1",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &DictionaryEntry{Surface: "Acrolinx", Scope: "guidanceProfile", Language: "en", GuidanceProfileID: "en-1"}, entry)
+}
+
+func TestAddToDictionaryError(t *testing.T) {
+	mux, server, client := setup(t)
+	defer teardown(server)
+
+	mux.HandleFunc("/api/v1/dictionary/submit", func(w http.ResponseWriter, r *http.Request) {
+		mustWriteHTTPResponse(t, w, "error.json")
+	})
+
+	_, _, err := client.Dictionary.AddToDictionary(context.Background(), &AddToDictionaryOptions{Surface: "x"})
+	assert.EqualError(t, err, "Please provide a valid signature in the X-Acrolinx-Client header.")
+}
+
+func TestAddToDictionaryCancelled(t *testing.T) {
+	mux, server, client := setup(t)
+	defer teardown(server)
+
+	mux.HandleFunc("/api/v1/dictionary/submit", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("unexpected request")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err := client.Dictionary.AddToDictionary(ctx, &AddToDictionaryOptions{Surface: "x"})
+	assert.ErrorIs(t, err, context.Canceled)
+}
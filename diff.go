@@ -0,0 +1,238 @@
+package acrolinx
+
+import (
+	"fmt"
+	"strings"
+)
+
+const defaultDiffContext = 3
+
+type DiffOptions struct {
+	// Context is the number of unchanged lines shown around each
+	// change. Defaults to 3 if no options are given.
+	Context int
+}
+
+type diffOp struct {
+	kind byte
+	line string
+
+	// Line indexes in the original and modified content before the
+	// operation.
+	a int
+	b int
+}
+
+// UnifiedDiff renders the changes between original and modified as a
+// unified diff with git headers, which can be applied with git apply or
+// patch -p1. It returns an empty string if there are no changes.
+func UnifiedDiff(path string, original, modified string, opts *DiffOptions) string {
+	if original == modified {
+		return ""
+	}
+
+	context := defaultDiffContext
+	if opts != nil {
+		context = max(opts.Context, 0)
+	}
+
+	ops := diffLines(splitLines(original), splitLines(modified))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "diff --git a/%s b/%s\n", path, path)
+	fmt.Fprintf(&b, "--- a/%s\n", path)
+	fmt.Fprintf(&b, "+++ b/%s\n", path)
+
+	for _, h := range diffHunks(ops, context) {
+		writeHunk(&b, ops[h[0]:h[1]])
+	}
+
+	return b.String()
+}
+
+// FixPatch applies fixes to content and renders the result as a patch
+// for the file at path.
+func FixPatch(path string, content string, fixes []*Fix, opts *DiffOptions) (string, *FixResult) {
+	result := ApplyFixes(content, fixes)
+	return UnifiedDiff(path, content, result.Content, opts), result
+}
+
+// splitLines splits s into lines, keeping the line endings.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// diffLines computes the shortest edit script turning a into b with
+// Myers' algorithm.
+func diffLines(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []diffOp
+	for i := 0; i < prefix; i++ {
+		ops = append(ops, diffOp{' ', a[i], i, i})
+	}
+
+	for _, op := range myersDiff(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]) {
+		op.a += prefix
+		op.b += prefix
+		ops = append(ops, op)
+	}
+
+	for i := suffix; i > 0; i-- {
+		ops = append(ops, diffOp{' ', a[len(a)-i], len(a) - i, len(b) - i})
+	}
+
+	return ops
+}
+
+func myersDiff(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+	var trace [][]int
+
+search:
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, append([]int(nil), v...))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	var reversed []diffOp
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			reversed = append(reversed, diffOp{' ', a[x], x, y})
+		}
+
+		if x == prevX {
+			y--
+			reversed = append(reversed, diffOp{'+', b[y], x, y})
+		} else {
+			x--
+			reversed = append(reversed, diffOp{'-', a[x], x, y})
+		}
+	}
+
+	for x > 0 && y > 0 {
+		x--
+		y--
+		reversed = append(reversed, diffOp{' ', a[x], x, y})
+	}
+
+	ops := make([]diffOp, len(reversed))
+	for i, op := range reversed {
+		ops[len(ops)-1-i] = op
+	}
+
+	return ops
+}
+
+// diffHunks returns the start and end indexes of ops making up each
+// hunk, given the number of context lines around changes.
+func diffHunks(ops []diffOp, context int) [][2]int {
+	var hunks [][2]int
+
+	for i, op := range ops {
+		if op.kind == ' ' {
+			continue
+		}
+
+		start, end := max(i-context, 0), min(i+1+context, len(ops))
+		if len(hunks) > 0 && start <= hunks[len(hunks)-1][1] {
+			hunks[len(hunks)-1][1] = end
+			continue
+		}
+
+		hunks = append(hunks, [2]int{start, end})
+	}
+
+	return hunks
+}
+
+func writeHunk(b *strings.Builder, ops []diffOp) {
+	aStart, bStart := ops[0].a, ops[0].b
+	aLen, bLen := 0, 0
+	for _, op := range ops {
+		if op.kind != '+' {
+			aLen++
+		}
+		if op.kind != '-' {
+			bLen++
+		}
+	}
+
+	fmt.Fprintf(b, "@@ -%s +%s @@\n", hunkRange(aStart, aLen), hunkRange(bStart, bLen))
+
+	for _, op := range ops {
+		b.WriteByte(op.kind)
+		b.WriteString(op.line)
+		if !strings.HasSuffix(op.line, "\n") {
+			b.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats the line range of a hunk. Empty ranges refer to
+// the line before them.
+func hunkRange(start, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+
+	if length == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
@@ -0,0 +1,129 @@
+package acrolinx
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	original := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\n"
+	modified := "one\n2\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\neleven\n"
+
+	expected := `diff --git a/numbers.txt b/numbers.txt
+--- a/numbers.txt
++++ b/numbers.txt
+@@ -1,5 +1,5 @@
+ one
+-two
++2
+ three
+ four
+ five
+@@ -8,3 +8,4 @@
+ eight
+ nine
+ ten
++eleven
+`
+	assert.Equal(t, expected, UnifiedDiff("numbers.txt", original, modified, nil))
+}
+
+func TestUnifiedDiffWithoutContext(t *testing.T) {
+	expected := `diff --git a/a.txt b/a.txt
+--- a/a.txt
++++ b/a.txt
+@@ -2 +2 @@
+-b
++B
+@@ -3,0 +4 @@
++d
+`
+	assert.Equal(t, expected, UnifiedDiff("a.txt", "a\nb\nc\n", "a\nB\nc\nd\n", &DiffOptions{Context: 0}))
+}
+
+func TestUnifiedDiffNoNewlineAtEnd(t *testing.T) {
+	expected := `diff --git a/a.txt b/a.txt
+--- a/a.txt
++++ b/a.txt
+@@ -1,2 +1,2 @@
+ a
+-b
+\ No newline at end of file
++c
+\ No newline at end of file
+`
+	assert.Equal(t, expected, UnifiedDiff("a.txt", "a\nb", "a\nc", nil))
+}
+
+func TestUnifiedDiffNoChanges(t *testing.T) {
+	assert.Equal(t, "", UnifiedDiff("a.txt", "same\n", "same\n", nil))
+}
+
+func TestUnifiedDiffEmptyFiles(t *testing.T) {
+	assert.Contains(t, UnifiedDiff("a.txt", "", "new\n", nil), "@@ -0,0 +1 @@\n+new\n")
+	assert.Contains(t, UnifiedDiff("a.txt", "old\n", "", nil), "@@ -1 +0,0 @@\n-old\n")
+}
+
+func TestDiffLines(t *testing.T) {
+	ops := diffLines(strings.Split("abcabba", ""), strings.Split("cbabac", ""))
+
+	var a, b strings.Builder
+	edits := 0
+	for _, op := range ops {
+		if op.kind != '+' {
+			a.WriteString(op.line)
+		}
+		if op.kind != '-' {
+			b.WriteString(op.line)
+		}
+		if op.kind != ' ' {
+			edits++
+		}
+	}
+
+	assert.Equal(t, "abcabba", a.String())
+	assert.Equal(t, "cbabac", b.String())
+	assert.Equal(t, 5, edits)
+}
+
+func TestFixPatch(t *testing.T) {
+	content := "# Title\n\nIt is not a utilise test.\n\nSecond paragraph.\n"
+	issue := fixTestIssue([]*Match{{OriginalPart: "utilise", OriginalBegin: 21, OriginalEnd: 28}}, "use")
+
+	patch, result := FixPatch("docs/README.md", content, SuggestedFixes([]*Issue{issue}), &DiffOptions{Context: 1})
+
+	assert.Len(t, result.Applied, 1)
+	assert.Equal(t, `diff --git a/docs/README.md b/docs/README.md
+--- a/docs/README.md
++++ b/docs/README.md
+@@ -2,3 +2,3 @@
+ 
+-It is not a utilise test.
++It is not a use test.
+ 
+`, patch)
+
+	git, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "docs"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "docs/README.md"), []byte(content), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "fix.patch"), []byte(patch), 0o644))
+
+	cmd := exec.Command(git, "apply", "fix.patch")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	assert.NoError(t, err, string(out))
+
+	fixed, err := os.ReadFile(filepath.Join(dir, "docs/README.md"))
+	assert.NoError(t, err)
+	assert.Equal(t, result.Content, string(fixed))
+}
@@ -0,0 +1,211 @@
+package acrolinx
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// IssueRecordSchemaVersion is the version of the IssueRecord schema. It
+// is increased whenever fields are renamed, removed or change meaning;
+// new fields may be added without changing it.
+const IssueRecordSchemaVersion = 1
+
+// IssueRecord is a flat representation of an issue for analytics.
+// Offsets are UTF-16 code units, lines and columns count runes and
+// start at one. Issues without matches have no offsets.
+type IssueRecord struct {
+	SchemaVersion   int    `json:"schema_version"`
+	Document        string `json:"document"`
+	CheckID         string `json:"check_id"`
+	GoalID          string `json:"goal_id"`
+	GoalName        string `json:"goal_name"`
+	InternalName    string `json:"internal_name"`
+	Title           string `json:"title"`
+	Surface         string `json:"surface"`
+	Severity        string `json:"severity"`
+	Parent          string `json:"parent"`
+	ReadOnly        bool   `json:"read_only"`
+	Begin           *int   `json:"begin"`
+	End             *int   `json:"end"`
+	Line            *int   `json:"line"`
+	Column          *int   `json:"column"`
+	SuggestionCount int    `json:"suggestion_count"`
+	Score           *int   `json:"score"`
+	GoalScore       *int   `json:"goal_score"`
+}
+
+var issueRecordColumns = []string{
+	"schema_version",
+	"document",
+	"check_id",
+	"goal_id",
+	"goal_name",
+	"internal_name",
+	"title",
+	"surface",
+	"severity",
+	"parent",
+	"read_only",
+	"begin",
+	"end",
+	"line",
+	"column",
+	"suggestion_count",
+	"score",
+	"goal_score",
+}
+
+// NewIssueRecords flattens the issues of docs into records. Sub-issues
+// follow their parent issue and refer to it by its internal name.
+func NewIssueRecords(docs []*CheckedDocument) []*IssueRecord {
+	var records []*IssueRecord
+
+	for _, doc := range sortedDocuments(docs) {
+		if doc.Result == nil {
+			continue
+		}
+
+		base := IssueRecord{
+			SchemaVersion: IssueRecordSchemaVersion,
+			Document:      doc.reference(),
+			CheckID:       doc.Result.ID,
+		}
+		if doc.Result.Quality != nil {
+			base.Score = intPtr(doc.Result.Quality.Score)
+		}
+
+		x := NewPositionIndex(doc.Content)
+		for _, issue := range doc.locatedIssues() {
+			records = doc.appendIssueRecords(records, x, base, issue.Issue, "")
+		}
+	}
+
+	return records
+}
+
+func (d *CheckedDocument) appendIssueRecords(records []*IssueRecord, x *PositionIndex, base IssueRecord, issue *Issue, parent string) []*IssueRecord {
+	r := base
+	r.GoalID = issue.GoalID
+	r.InternalName = issue.InternalName
+	r.Title = issueTitle(issue)
+	r.Surface = issue.DisplaySurface
+	r.Severity = d.severity(issue)
+	r.Parent = parent
+	r.ReadOnly = issue.ReadOnly
+	r.SuggestionCount = len(issue.Suggestions)
+
+	if g := d.goal(issue); g != nil {
+		r.GoalName = g.DisplayName
+	}
+
+	if d.Result.Quality != nil {
+		for _, s := range d.Result.Quality.ScoresByGoal {
+			if s.ID == issue.GoalID {
+				r.GoalScore = intPtr(s.Score)
+			}
+		}
+	}
+
+	if rng, ok := d.issueRange(x, issue); ok {
+		r.Begin = intPtr(rng.Start.UTF16)
+		r.End = intPtr(rng.End.UTF16)
+		r.Line = intPtr(rng.Start.Line)
+		r.Column = intPtr(rng.Start.Column)
+	}
+
+	records = append(records, &r)
+	for _, sub := range issue.SubIssues {
+		records = d.appendIssueRecords(records, x, base, sub, issueRuleID(issue))
+	}
+
+	return records
+}
+
+// reference returns the path of the document, or else the reference it
+// was checked with.
+func (d *CheckedDocument) reference() string {
+	if d.Path != "" {
+		return d.slashPath()
+	}
+
+	if d.Result != nil && d.Result.Document != nil && d.Result.Document.DisplayInfo != nil {
+		return d.Result.Document.DisplayInfo.Reference
+	}
+
+	return ""
+}
+
+// WriteIssuesCSV writes the issue records of docs to w as CSV with a
+// header row. Missing values are empty.
+func WriteIssuesCSV(w io.Writer, docs []*CheckedDocument) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(issueRecordColumns); err != nil {
+		return fmt.Errorf("Error writing CSV: %w", err)
+	}
+
+	for _, r := range NewIssueRecords(docs) {
+		if err := cw.Write(r.csvRow()); err != nil {
+			return fmt.Errorf("Error writing CSV: %w", err)
+		}
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("Error writing CSV: %w", err)
+	}
+
+	return nil
+}
+
+// WriteIssuesNDJSON writes the issue records of docs to w as
+// newline-delimited JSON. Missing values are null.
+func WriteIssuesNDJSON(w io.Writer, docs []*CheckedDocument) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+
+	for _, r := range NewIssueRecords(docs) {
+		if err := enc.Encode(r); err != nil {
+			return fmt.Errorf("Error writing NDJSON: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *IssueRecord) csvRow() []string {
+	return []string{
+		strconv.Itoa(r.SchemaVersion),
+		r.Document,
+		r.CheckID,
+		r.GoalID,
+		r.GoalName,
+		r.InternalName,
+		r.Title,
+		r.Surface,
+		r.Severity,
+		r.Parent,
+		strconv.FormatBool(r.ReadOnly),
+		formatIntPtr(r.Begin),
+		formatIntPtr(r.End),
+		formatIntPtr(r.Line),
+		formatIntPtr(r.Column),
+		strconv.Itoa(r.SuggestionCount),
+		formatIntPtr(r.Score),
+		formatIntPtr(r.GoalScore),
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func formatIntPtr(i *int) string {
+	if i == nil {
+		return ""
+	}
+
+	return strconv.Itoa(*i)
+}
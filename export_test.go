@@ -0,0 +1,75 @@
+package acrolinx
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// exportTestDocuments adds a sub-issue to the contraction issue of the
+// report test documents.
+func exportTestDocuments() []*CheckedDocument {
+	docs := reportTestDocuments()
+	contraction := docs[0].Result.Issues[0]
+	contraction.SubIssues = []*Issue{{
+		GoalID:         "CONSISTENCY",
+		InternalName:   "negation",
+		DisplaySurface: "not",
+		PositionalInformation: &PositionalInformation{
+			Matches: []*Match{contraction.PositionalInformation.Matches[2]},
+		},
+	}}
+
+	return docs
+}
+
+func TestWriteIssuesCSV(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, WriteIssuesCSV(&buf, exportTestDocuments()))
+	assertGolden(t, "issues.csv", buf.String())
+
+	rows, err := csv.NewReader(strings.NewReader(buf.String())).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, rows, 6)
+	assert.Equal(t, issueRecordColumns, rows[0])
+}
+
+func TestWriteIssuesNDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, WriteIssuesNDJSON(&buf, exportTestDocuments()))
+	assertGolden(t, "issues.ndjson", buf.String())
+}
+
+func TestNewIssueRecords(t *testing.T) {
+	records := NewIssueRecords(exportTestDocuments())
+	assert.Len(t, records, 5)
+
+	long := records[0]
+	assert.Equal(t, "document_too_long", long.InternalName)
+	assert.Nil(t, long.Begin)
+	assert.Equal(t, 74, *long.Score)
+	assert.Equal(t, 60, *long.GoalScore)
+
+	sub := records[4]
+	assert.Equal(t, "negation", sub.InternalName)
+	assert.Equal(t, "contraction", sub.Parent)
+	assert.Equal(t, "Consistency", sub.GoalName)
+	assert.Equal(t, 4, *sub.Line)
+	assert.Equal(t, 10, *sub.Column)
+}
+
+func TestIssueRecordColumns(t *testing.T) {
+	typ := reflect.TypeOf(IssueRecord{})
+
+	var tags []string
+	for i := 0; i < typ.NumField(); i++ {
+		tags = append(tags, typ.Field(i).Tag.Get("json"))
+	}
+
+	assert.Equal(t, issueRecordColumns, tags)
+	assert.Len(t, (&IssueRecord{}).csvRow(), len(issueRecordColumns))
+}
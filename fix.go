@@ -0,0 +1,173 @@
+package acrolinx
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Fix selects the suggestion to apply for an issue.
+type Fix struct {
+	Issue      *Issue
+	Suggestion *Suggestion
+}
+
+// Edit replaces the text between two UTF-16 offsets of the content.
+type Edit struct {
+	Begin       int
+	End         int
+	Original    string
+	Replacement string
+
+	byteBegin int
+	byteEnd   int
+}
+
+type AppliedFix struct {
+	Fix   *Fix
+	Edits []*Edit
+}
+
+type RejectedFix struct {
+	Fix    *Fix
+	Reason string
+}
+
+type FixResult struct {
+	Content  string
+	Applied  []*AppliedFix
+	Rejected []*RejectedFix
+}
+
+// SuggestedFixes selects the first suggestion of every issue that has
+// any.
+func SuggestedFixes(issues []*Issue) []*Fix {
+	var fixes []*Fix
+	for _, issue := range issues {
+		if len(issue.Suggestions) > 0 {
+			fixes = append(fixes, &Fix{issue, issue.Suggestions[0]})
+		}
+	}
+
+	return fixes
+}
+
+// ApplyFixes applies fixes to content. The replacements of a
+// suggestion correspond to the matches of its issue one by one; an
+// empty replacement removes the match. All edits of a fix are applied
+// or none is. A fix is rejected if its issue is read-only, its
+// suggestion has a different number of replacements than matches, its
+// matches don't match the content, or its edits overlap those of a fix
+// earlier in the list.
+func ApplyFixes(content string, fixes []*Fix) *FixResult {
+	x := NewPositionIndex(content)
+	result := &FixResult{}
+	var accepted []*Edit
+
+	for _, fix := range fixes {
+		edits, err := fixEdits(x, content, fix)
+		if err == nil {
+			err = checkConflicts(edits, accepted)
+		}
+
+		if err != nil {
+			result.Rejected = append(result.Rejected, &RejectedFix{fix, err.Error()})
+			continue
+		}
+
+		accepted = append(accepted, edits...)
+		result.Applied = append(result.Applied, &AppliedFix{fix, edits})
+	}
+
+	sort.SliceStable(accepted, func(i, j int) bool {
+		return accepted[i].byteBegin < accepted[j].byteBegin
+	})
+
+	var b strings.Builder
+	pos := 0
+	for _, e := range accepted {
+		b.WriteString(content[pos:e.byteBegin])
+		b.WriteString(e.Replacement)
+		pos = e.byteEnd
+	}
+	b.WriteString(content[pos:])
+	result.Content = b.String()
+
+	return result
+}
+
+func fixEdits(x *PositionIndex, content string, fix *Fix) ([]*Edit, error) {
+	issue := fix.Issue
+	switch {
+	case issue.ReadOnly:
+		return nil, errors.New("issue is read-only")
+	case fix.Suggestion == nil:
+		return nil, errors.New("no suggestion selected")
+	case issue.PositionalInformation == nil || len(issue.PositionalInformation.Matches) == 0:
+		return nil, errors.New("issue has no matches")
+	case len(fix.Suggestion.Replacements) != len(issue.PositionalInformation.Matches):
+		return nil, fmt.Errorf("suggestion has %d replacements for %d matches",
+			len(fix.Suggestion.Replacements), len(issue.PositionalInformation.Matches))
+	}
+
+	var edits []*Edit
+	for i, m := range issue.PositionalInformation.Matches {
+		if m.OriginalBegin < 0 || m.OriginalEnd < m.OriginalBegin || m.OriginalEnd > x.Len() {
+			return nil, fmt.Errorf("match %d-%d is out of bounds", m.OriginalBegin, m.OriginalEnd)
+		}
+
+		begin, end := x.FromUTF16(m.OriginalBegin).Byte, x.FromUTF16(m.OriginalEnd).Byte
+		original := content[begin:end]
+		if m.OriginalPart != "" && original != m.OriginalPart {
+			return nil, fmt.Errorf("content at %d-%d is %q, expected %q", m.OriginalBegin, m.OriginalEnd, original, m.OriginalPart)
+		}
+
+		replacement := fix.Suggestion.Replacements[i]
+		if replacement == original {
+			continue
+		}
+
+		edit := &Edit{
+			Begin:       m.OriginalBegin,
+			End:         m.OriginalEnd,
+			Original:    original,
+			Replacement: replacement,
+			byteBegin:   begin,
+			byteEnd:     end,
+		}
+		if err := checkConflicts([]*Edit{edit}, edits); err != nil {
+			return nil, err
+		}
+		edits = append(edits, edit)
+	}
+
+	if len(edits) == 0 {
+		return nil, errors.New("suggestion doesn't change the content")
+	}
+
+	return edits, nil
+}
+
+func checkConflicts(edits []*Edit, accepted []*Edit) error {
+	for _, e := range edits {
+		for _, a := range accepted {
+			if editsOverlap(e, a) {
+				return fmt.Errorf("edit at %d-%d overlaps edit at %d-%d", e.Begin, e.End, a.Begin, a.End)
+			}
+		}
+	}
+
+	return nil
+}
+
+// editsOverlap reports whether two edits touch the same text. Two
+// insertions at the same position overlap as well, since their order
+// would be undefined.
+func editsOverlap(a, b *Edit) bool {
+	if a.Begin == b.Begin {
+		return true
+	}
+
+	return a.Begin < b.End && b.Begin < a.End
+}
@@ -0,0 +1,105 @@
+package acrolinx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fixTestIssue(matches []*Match, replacements ...string) *Issue {
+	return &Issue{
+		PositionalInformation: &PositionalInformation{Matches: matches},
+		Suggestions:           []*Suggestion{{Surface: replacements[0], Replacements: replacements}},
+	}
+}
+
+func TestApplyFixes(t *testing.T) {
+	content := "It is not 😀 a utilise test."
+
+	contraction := fixTestIssue([]*Match{
+		{OriginalPart: "is", OriginalBegin: 3, OriginalEnd: 5},
+		{OriginalPart: " ", OriginalBegin: 5, OriginalEnd: 6},
+		{OriginalPart: "not", OriginalBegin: 6, OriginalEnd: 9},
+	}, "isn’t", "", "")
+	spelling := fixTestIssue([]*Match{
+		{OriginalPart: "utilise", OriginalBegin: 15, OriginalEnd: 22},
+	}, "use")
+
+	result := ApplyFixes(content, SuggestedFixes([]*Issue{contraction, spelling, {}}))
+
+	assert.Equal(t, "It isn’t 😀 a use test.", result.Content)
+	assert.Len(t, result.Applied, 2)
+	assert.Empty(t, result.Rejected)
+	assert.Equal(t, []*Edit{
+		{Begin: 3, End: 5, Original: "is", Replacement: "isn’t", byteBegin: 3, byteEnd: 5},
+		{Begin: 5, End: 6, Original: " ", Replacement: "", byteBegin: 5, byteEnd: 6},
+		{Begin: 6, End: 9, Original: "not", Replacement: "", byteBegin: 6, byteEnd: 9},
+	}, result.Applied[0].Edits)
+}
+
+func TestApplyFixesRejected(t *testing.T) {
+	content := "This is a simple sentence."
+
+	simple := fixTestIssue([]*Match{{OriginalPart: "simple", OriginalBegin: 10, OriginalEnd: 16}}, "plain")
+	overlapping := fixTestIssue([]*Match{{OriginalPart: "simple sentence", OriginalBegin: 10, OriginalEnd: 25}}, "sentence")
+	readOnly := fixTestIssue([]*Match{{OriginalPart: "This", OriginalBegin: 0, OriginalEnd: 4}}, "That")
+	readOnly.ReadOnly = true
+	stale := fixTestIssue([]*Match{{OriginalPart: "was", OriginalBegin: 5, OriginalEnd: 7}}, "is")
+	outOfBounds := fixTestIssue([]*Match{{OriginalPart: "end", OriginalBegin: 30, OriginalEnd: 33}}, "stop")
+	noop := fixTestIssue([]*Match{{OriginalPart: "a", OriginalBegin: 8, OriginalEnd: 9}}, "a")
+	noMatches := fixTestIssue(nil, "x")
+	incomplete := fixTestIssue([]*Match{
+		{OriginalPart: "This", OriginalBegin: 0, OriginalEnd: 4},
+		{OriginalPart: "sentence", OriginalBegin: 17, OriginalEnd: 25},
+	}, "That")
+
+	result := ApplyFixes(content, []*Fix{
+		{simple, simple.Suggestions[0]},
+		{overlapping, overlapping.Suggestions[0]},
+		{readOnly, readOnly.Suggestions[0]},
+		{stale, stale.Suggestions[0]},
+		{outOfBounds, outOfBounds.Suggestions[0]},
+		{noop, noop.Suggestions[0]},
+		{noMatches, noMatches.Suggestions[0]},
+		{incomplete, incomplete.Suggestions[0]},
+		{simple, nil},
+	})
+
+	assert.Equal(t, "This is a plain sentence.", result.Content)
+	assert.Len(t, result.Applied, 1)
+
+	var reasons []string
+	for _, r := range result.Rejected {
+		reasons = append(reasons, r.Reason)
+	}
+	assert.Equal(t, []string{
+		"edit at 10-25 overlaps edit at 10-16",
+		"issue is read-only",
+		`content at 5-7 is "is", expected "was"`,
+		"match 30-33 is out of bounds",
+		"suggestion doesn't change the content",
+		"issue has no matches",
+		"suggestion has 1 replacements for 2 matches",
+		"no suggestion selected",
+	}, reasons)
+}
+
+func TestApplyFixesInsertions(t *testing.T) {
+	content := "In most cases it works."
+
+	comma := fixTestIssue([]*Match{{OriginalBegin: 13, OriginalEnd: 13}}, ",")
+	other := fixTestIssue([]*Match{{OriginalBegin: 13, OriginalEnd: 13}}, ";")
+
+	result := ApplyFixes(content, SuggestedFixes([]*Issue{comma, other}))
+	assert.Equal(t, "In most cases, it works.", result.Content)
+	assert.Len(t, result.Rejected, 1)
+}
+
+func TestApplyFixesUTF16Offsets(t *testing.T) {
+	content := "😀 日本 colour"
+
+	issue := fixTestIssue([]*Match{{OriginalPart: "colour", OriginalBegin: 6, OriginalEnd: 12}}, "color")
+
+	result := ApplyFixes(content, SuggestedFixes([]*Issue{issue}))
+	assert.Equal(t, "😀 日本 color", result.Content)
+}
@@ -0,0 +1,75 @@
+package acrolinx
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteGitHubActions writes a workflow command for every issue in docs,
+// which GitHub Actions shows as an annotation on the affected lines.
+// Required issues are errors, other scored issues warnings and the rest
+// notices.
+func WriteGitHubActions(w io.Writer, docs []*CheckedDocument) error {
+	for _, doc := range sortedDocuments(docs) {
+		path := doc.slashPath()
+
+		for _, issue := range doc.locatedIssues() {
+			props := []string{"file=" + escapeGitHubProperty(path)}
+			if issue.Located {
+				props = append(props,
+					fmt.Sprintf("line=%d", issue.Range.Start.Line),
+					fmt.Sprintf("col=%d", issue.Range.Start.Column),
+					fmt.Sprintf("endLine=%d", issue.Range.End.Line),
+					fmt.Sprintf("endColumn=%d", issue.Range.End.Column),
+				)
+			}
+			props = append(props, "title="+escapeGitHubProperty("Acrolinx: "+issueRuleID(issue.Issue)))
+
+			_, err := fmt.Fprintf(w, "::%s %s::%s\n",
+				githubCommand(doc.severity(issue.Issue)),
+				strings.Join(props, ","),
+				escapeGitHubData(issueTitle(issue.Issue)),
+			)
+			if err != nil {
+				return fmt.Errorf("Error writing GitHub Actions annotations: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func githubCommand(severity string) string {
+	switch severity {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	}
+
+	return "notice"
+}
+
+var (
+	githubDataEscaper = strings.NewReplacer(
+		"%", "%25",
+		"\r", "%0D",
+		"\n", "%0A",
+	)
+	githubPropertyEscaper = strings.NewReplacer(
+		"%", "%25",
+		"\r", "%0D",
+		"\n", "%0A",
+		":", "%3A",
+		",", "%2C",
+	)
+)
+
+func escapeGitHubData(s string) string {
+	return githubDataEscaper.Replace(s)
+}
+
+func escapeGitHubProperty(s string) string {
+	return githubPropertyEscaper.Replace(s)
+}
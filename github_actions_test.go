@@ -0,0 +1,19 @@
+package acrolinx
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteGitHubActions(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, WriteGitHubActions(&buf, reportTestDocuments()))
+	assertGolden(t, "report.github.txt", buf.String())
+}
+
+func TestEscapeGitHubCommands(t *testing.T) {
+	assert.Equal(t, "100%25 sure%0Anext: line, done", escapeGitHubData("100% sure\nnext: line, done"))
+	assert.Equal(t, "a%3Ab%2Cc%0D%0A", escapeGitHubProperty("a:b,c\r\n"))
+}
@@ -0,0 +1,102 @@
+package acrolinx
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// GitLabIssue is an issue in the GitLab Code Quality report format.
+type GitLabIssue struct {
+	Description string          `json:"description"`
+	CheckName   string          `json:"check_name"`
+	Fingerprint string          `json:"fingerprint"`
+	Severity    string          `json:"severity"`
+	Location    *GitLabLocation `json:"location"`
+}
+
+type GitLabLocation struct {
+	Path  string       `json:"path"`
+	Lines *GitLabLines `json:"lines"`
+}
+
+type GitLabLines struct {
+	Begin int `json:"begin"`
+	End   int `json:"end,omitempty"`
+}
+
+// NewGitLabCodeQuality converts the issues of docs into a GitLab Code
+// Quality report. Fingerprints are derived from the issue hash reported
+// by the platform, so an issue keeps its fingerprint while the text
+// around it changes. Issues without matches are reported on line 1.
+func NewGitLabCodeQuality(docs []*CheckedDocument) []*GitLabIssue {
+	issues := []*GitLabIssue{}
+	seen := make(map[string]int)
+
+	for _, doc := range sortedDocuments(docs) {
+		path := doc.slashPath()
+
+		for _, issue := range doc.locatedIssues() {
+			lines := &GitLabLines{Begin: 1}
+			if issue.Located {
+				lines = &GitLabLines{Begin: issue.Range.Start.Line, End: issue.Range.End.Line}
+			}
+
+			// The same issue can occur several times in a document, but
+			// fingerprints must be unique within a report.
+			base := path + "\x00" + gitlabIssueKey(issue.Issue)
+			key := base
+			if n := seen[base]; n > 0 {
+				key += "\x00" + strconv.Itoa(n)
+			}
+			seen[base]++
+			sum := sha256.Sum256([]byte(key))
+
+			issues = append(issues, &GitLabIssue{
+				Description: issueTitle(issue.Issue),
+				CheckName:   "acrolinx." + issueRuleID(issue.Issue),
+				Fingerprint: hex.EncodeToString(sum[:16]),
+				Severity:    gitlabSeverity(doc.severity(issue.Issue)),
+				Location:    &GitLabLocation{Path: path, Lines: lines},
+			})
+		}
+	}
+
+	return issues
+}
+
+// WriteGitLabCodeQuality writes the GitLab Code Quality report for
+// docs to w.
+func WriteGitLabCodeQuality(w io.Writer, docs []*CheckedDocument) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(NewGitLabCodeQuality(docs)); err != nil {
+		return fmt.Errorf("Error writing GitLab Code Quality report: %w", err)
+	}
+
+	return nil
+}
+
+// gitlabIssueKey identifies an issue independently of its position.
+func gitlabIssueKey(issue *Issue) string {
+	if info := issue.PositionalInformation; info != nil && info.Hashes != nil && info.Hashes.Issue != "" {
+		return info.Hashes.Issue
+	}
+
+	return issueRuleID(issue) + "\x00" + issue.DisplaySurface
+}
+
+func gitlabSeverity(severity string) string {
+	switch severity {
+	case SeverityError:
+		return "major"
+	case SeverityWarning:
+		return "minor"
+	}
+
+	return "info"
+}
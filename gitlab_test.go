@@ -0,0 +1,49 @@
+package acrolinx
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteGitLabCodeQuality(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, WriteGitLabCodeQuality(&buf, reportTestDocuments()))
+	assertGolden(t, "report.gitlab.json", buf.String())
+}
+
+func TestGitLabFingerprints(t *testing.T) {
+	docs := reportTestDocuments()
+	issues := NewGitLabCodeQuality(docs)
+
+	// Fingerprints don't change when the issue moves.
+	moved := reportTestDocuments()
+	moved[0].Content = "\n" + moved[0].Content
+	for _, issue := range moved[0].Result.Issues {
+		if issue.PositionalInformation == nil {
+			continue
+		}
+		for _, m := range issue.PositionalInformation.Matches {
+			m.OriginalBegin++
+			m.OriginalEnd++
+		}
+	}
+	movedIssues := NewGitLabCodeQuality(moved)
+	for i := range issues {
+		assert.Equal(t, issues[i].Fingerprint, movedIssues[i].Fingerprint)
+		if issues[i].Location.Lines.End > 0 {
+			assert.Equal(t, issues[i].Location.Lines.Begin+1, movedIssues[i].Location.Lines.Begin)
+		}
+	}
+
+	// Repeated issues still get unique fingerprints.
+	doc := docs[0]
+	doc.Result.Issues = append(doc.Result.Issues, doc.Result.Issues[0], doc.Result.Issues[0])
+	seen := make(map[string]bool)
+	for _, issue := range NewGitLabCodeQuality(docs) {
+		assert.False(t, seen[issue.Fingerprint], "duplicate fingerprint %s", issue.Fingerprint)
+		seen[issue.Fingerprint] = true
+	}
+	assert.Len(t, seen, 6)
+}
@@ -0,0 +1,206 @@
+package acrolinx
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type htmlReport struct {
+	Documents []*htmlDocument
+}
+
+type htmlDocument struct {
+	Path    string
+	Quality *Quality
+	Issues  int
+	Goals   []*htmlGoal
+	Content template.HTML
+}
+
+type htmlGoal struct {
+	Name   string
+	Color  string
+	Score  *int
+	Issues []*htmlIssue
+}
+
+type htmlIssue struct {
+	Anchor      string
+	Location    string
+	Title       string
+	Severity    string
+	Guidance    string
+	Suggestions []string
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Acrolinx Report</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2em auto; max-width: 60em; color: #212121; }
+h2 { display: flex; align-items: center; gap: .5em; border-bottom: 1px solid #e0e0e0; }
+.badge { border-radius: 1em; padding: .1em .6em; font-size: .8em; color: #fff; background: #9e9e9e; }
+.badge.red { background: #e53935; }
+.badge.yellow { background: #f9a825; }
+.badge.green { background: #43a047; }
+.goal { border-left: .3em solid #9e9e9e; padding-left: 1em; margin: 1em 0; }
+.goal h3 { display: flex; gap: .5em; margin: 0; }
+.issue { margin: .5em 0; }
+.issue .location { font-family: monospace; color: #616161; }
+.issue .severity { text-transform: uppercase; font-size: .75em; }
+.issue .guidance { white-space: pre-line; color: #424242; margin: .2em 0; }
+pre.content { white-space: pre-wrap; background: #fafafa; border: 1px solid #e0e0e0; padding: 1em; }
+pre.content mark { background: none; border-bottom: .15em solid; }
+pre.content a { color: inherit; text-decoration: none; }
+</style>
+</head>
+<body>
+<h1>Acrolinx Report</h1>
+{{range .Documents}}
+<section>
+<h2>{{.Path}}{{with .Quality}} <span class="badge {{.Status}}">{{.Score}}</span>{{end}}</h2>
+<p>{{if eq .Issues 0}}No issues{{else if eq .Issues 1}}1 issue{{else}}{{.Issues}} issues{{end}}</p>
+{{range .Goals}}
+<div class="goal" style="border-color: {{.Color}}">
+<h3>{{.Name}}{{with .Score}} <span class="badge">{{.}}</span>{{end}}</h3>
+{{range .Issues}}
+<div class="issue" id="{{.Anchor}}">
+<span class="location">{{.Location}}</span> <span class="severity">{{.Severity}}</span> <strong>{{.Title}}</strong>
+{{with .Guidance}}<div class="guidance">{{.}}</div>{{end}}
+{{with .Suggestions}}<div class="suggestions">Suggestions: {{range $i, $s := .}}{{if $i}}, {{end}}<code>{{$s}}</code>{{end}}</div>{{end}}
+</div>
+{{end}}
+</div>
+{{end}}
+<pre class="content">{{.Content}}</pre>
+</section>
+{{end}}
+</body>
+</html>
+`))
+
+// WriteHTML writes a self-contained HTML page for docs to w. The issues
+// of every document are grouped by goal and highlighted in its content.
+func WriteHTML(w io.Writer, docs []*CheckedDocument) error {
+	report := &htmlReport{}
+	for i, doc := range sortedDocuments(docs) {
+		report.Documents = append(report.Documents, newHTMLDocument(fmt.Sprintf("doc%d", i), doc))
+	}
+
+	if err := htmlReportTemplate.Execute(w, report); err != nil {
+		return fmt.Errorf("Error writing HTML report: %w", err)
+	}
+
+	return nil
+}
+
+func newHTMLDocument(id string, doc *CheckedDocument) *htmlDocument {
+	d := &htmlDocument{Path: doc.slashPath()}
+	if doc.Result != nil {
+		d.Quality = doc.Result.Quality
+	}
+
+	x := NewPositionIndex(doc.Content)
+	goals := make(map[string]*htmlGoal)
+	var marks []htmlMark
+
+	for i, issue := range doc.locatedIssues() {
+		g, ok := goals[issue.GoalID]
+		if !ok {
+			g = newHTMLGoal(doc, issue.GoalID)
+			goals[issue.GoalID] = g
+			d.Goals = append(d.Goals, g)
+		}
+
+		hi := &htmlIssue{
+			Anchor:   fmt.Sprintf("%s-issue%d", id, i),
+			Location: issueLocation(d.Path, issue),
+			Title:    issueTitle(issue.Issue),
+			Severity: doc.severity(issue.Issue),
+			Guidance: htmlToText(issue.GuidanceHTML),
+		}
+		for _, s := range issue.Suggestions {
+			hi.Suggestions = append(hi.Suggestions, s.Surface)
+		}
+		g.Issues = append(g.Issues, hi)
+		d.Issues++
+
+		if issue.Located {
+			for _, m := range issue.PositionalInformation.Matches {
+				r := x.MatchRange(m)
+				marks = append(marks, htmlMark{r.Start.Byte, r.End.Byte, g.Color, hi.Anchor, hi.Title})
+			}
+		}
+	}
+
+	sort.SliceStable(d.Goals, func(i, j int) bool {
+		return d.Goals[i].Name < d.Goals[j].Name
+	})
+	d.Content = highlightContent(doc.Content, marks)
+
+	return d
+}
+
+func newHTMLGoal(doc *CheckedDocument, id string) *htmlGoal {
+	g := &htmlGoal{Name: id, Color: "#9e9e9e"}
+	if goal := doc.goal(&Issue{GoalID: id}); goal != nil {
+		g.Name = goal.DisplayName
+		if cssHexColor.MatchString(goal.Color) {
+			g.Color = goal.Color
+		}
+	}
+
+	if doc.Result != nil && doc.Result.Quality != nil {
+		for _, s := range doc.Result.Quality.ScoresByGoal {
+			if s.ID == id {
+				score := s.Score
+				g.Score = &score
+			}
+		}
+	}
+
+	return g
+}
+
+var cssHexColor = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}){1,2}$`)
+
+type htmlMark struct {
+	begin int
+	end   int
+	color string
+	href  string
+	title string
+}
+
+// highlightContent escapes content and wraps the text of every mark in
+// a link to its issue. Overlapping marks are cut off where the previous
+// one ends.
+func highlightContent(content string, marks []htmlMark) template.HTML {
+	sort.SliceStable(marks, func(i, j int) bool {
+		return marks[i].begin < marks[j].begin
+	})
+
+	var b strings.Builder
+	pos := 0
+	for _, m := range marks {
+		begin := max(m.begin, pos)
+		if begin >= m.end {
+			continue
+		}
+
+		b.WriteString(html.EscapeString(content[pos:begin]))
+		fmt.Fprintf(&b, `<a href="#%s" title="%s"><mark style="border-color: %s">%s</mark></a>`,
+			m.href, html.EscapeString(m.title), m.color, html.EscapeString(content[begin:m.end]))
+		pos = m.end
+	}
+	b.WriteString(html.EscapeString(content[pos:]))
+
+	return template.HTML(b.String())
+}
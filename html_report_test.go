@@ -0,0 +1,58 @@
+package acrolinx
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteHTML(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, WriteHTML(&buf, reportTestDocuments()))
+	assertGolden(t, "report.html", buf.String())
+
+	out := buf.String()
+	assert.Contains(t, out, `<span class="badge yellow">74</span>`)
+	assert.Contains(t, out, `<div class="goal" style="border-color: #ec407a">`)
+	assert.Contains(t, out, `<mark style="border-color: #ec407a">utilise</mark>`)
+}
+
+func TestWriteHTMLEscapes(t *testing.T) {
+	docs := []*CheckedDocument{{
+		Path:    "a<b>.md",
+		Content: "<script>alert(1)</script>",
+		Result: &CheckResult{
+			Goals: []*Goal{{ID: "G", DisplayName: "<i>Goal</i>", Color: "red;background:url(x)"}},
+			Issues: []*Issue{{
+				GoalID:          "G",
+				InternalName:    "rule",
+				DisplayNameHTML: "<b>&lt;script&gt;</b>",
+				PositionalInformation: &PositionalInformation{
+					Matches: []*Match{{OriginalBegin: 1, OriginalEnd: 7}},
+				},
+			}},
+		},
+	}}
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteHTML(&buf, docs))
+
+	out := buf.String()
+	assert.NotContains(t, out, "<script>")
+	assert.NotContains(t, out, "<i>Goal</i>")
+	assert.NotContains(t, out, "url(x)")
+	assert.Contains(t, out, `&lt;<a href="#doc0-issue0" title="&lt;script&gt;"><mark style="border-color: #9e9e9e">script</mark></a>&gt;`)
+}
+
+func TestHighlightContentOverlap(t *testing.T) {
+	marks := []htmlMark{
+		{4, 9, "#000", "b", "B"},
+		{0, 6, "#000", "a", "A"},
+	}
+
+	assert.Equal(t,
+		`<a href="#a" title="A"><mark style="border-color: #000">a &amp; bc</mark></a><a href="#b" title="B"><mark style="border-color: #000"> d </mark></a>e`,
+		string(highlightContent("a & bc d e", marks)),
+	)
+}
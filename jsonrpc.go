@@ -0,0 +1,132 @@
+package acrolinx
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"sync"
+)
+
+const (
+	jsonrpcParseError     = -32700
+	jsonrpcInvalidRequest = -32600
+	jsonrpcMethodNotFound = -32601
+	jsonrpcInvalidParams  = -32602
+	jsonrpcInternalError  = -32603
+
+	lspServerNotInitialized = -32002
+)
+
+// jsonrpcMessage is a JSON-RPC 2.0 request, response or notification.
+// Requests and responses have an ID, notifications don't.
+type jsonrpcMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *jsonrpcError   `json:"error,omitempty"`
+}
+
+type jsonrpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *jsonrpcError) Error() string {
+	return e.Message
+}
+
+// jsonrpcConn reads and writes JSON-RPC messages framed by
+// Content-Length headers, as used by the Language Server Protocol.
+// Writes are safe for concurrent use.
+type jsonrpcConn struct {
+	r *textproto.Reader
+
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func newJSONRPCConn(r io.Reader, w io.Writer) *jsonrpcConn {
+	return &jsonrpcConn{r: textproto.NewReader(bufio.NewReader(r)), w: w}
+}
+
+// read returns the next message. It returns io.EOF if the input ends
+// between messages.
+func (c *jsonrpcConn) read() (*jsonrpcMessage, error) {
+	header, err := c.r.ReadMIMEHeader()
+	if err == io.EOF && len(header) == 0 {
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error reading message header: %w", err)
+	}
+
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("Error reading message header: invalid Content-Length %q", header.Get("Content-Length"))
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(c.r.R, body); err != nil {
+		return nil, fmt.Errorf("Error reading message: %w", err)
+	}
+
+	var msg jsonrpcMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, &jsonrpcError{jsonrpcParseError, fmt.Sprintf("Error decoding message: %v", err)}
+	}
+
+	return &msg, nil
+}
+
+func (c *jsonrpcConn) write(msg *jsonrpcMessage) error {
+	msg.JSONRPC = "2.0"
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("Error encoding message: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := fmt.Fprintf(c.w, "Content-Length: %d\r\n\r\n%s", len(body), body); err != nil {
+		return fmt.Errorf("Error writing message: %w", err)
+	}
+
+	return nil
+}
+
+func (c *jsonrpcConn) notify(method string, params interface{}) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("Error encoding message: %w", err)
+	}
+
+	return c.write(&jsonrpcMessage{Method: method, Params: data})
+}
+
+// reply answers the request with the given ID. Errors that aren't
+// jsonrpcErrors are reported as internal errors.
+func (c *jsonrpcConn) reply(id json.RawMessage, result interface{}, err error) error {
+	msg := &jsonrpcMessage{ID: id}
+	if err != nil {
+		rpcErr, ok := err.(*jsonrpcError)
+		if !ok {
+			rpcErr = &jsonrpcError{jsonrpcInternalError, err.Error()}
+		}
+		msg.Error = rpcErr
+		return c.write(msg)
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("Error encoding message: %w", err)
+	}
+	msg.Result = data
+
+	return c.write(msg)
+}
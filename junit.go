@@ -0,0 +1,133 @@
+package acrolinx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+)
+
+type JUnitTestSuites struct {
+	XMLName  xml.Name          `xml:"testsuites"`
+	Name     string            `xml:"name,attr"`
+	Tests    int               `xml:"tests,attr"`
+	Failures int               `xml:"failures,attr"`
+	Errors   int               `xml:"errors,attr"`
+	Suites   []*JUnitTestSuite `xml:"testsuite"`
+}
+
+type JUnitTestSuite struct {
+	Name      string           `xml:"name,attr"`
+	Tests     int              `xml:"tests,attr"`
+	Failures  int              `xml:"failures,attr"`
+	Errors    int              `xml:"errors,attr"`
+	TestCases []*JUnitTestCase `xml:"testcase"`
+}
+
+type JUnitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *JUnitFailure `xml:"failure,omitempty"`
+	Error     *JUnitFailure `xml:"error,omitempty"`
+}
+
+type JUnitFailure struct {
+	Type    string `xml:"type,attr,omitempty"`
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// sortedDocuments returns docs sorted by path.
+func sortedDocuments(docs []*CheckedDocument) []*CheckedDocument {
+	sorted := append([]*CheckedDocument(nil), docs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Path < sorted[j].Path
+	})
+
+	return sorted
+}
+
+// issueLocation formats the location of issue as path:line:column,
+// or just the path if the issue has no matches.
+func issueLocation(path string, issue *locatedIssue) string {
+	if !issue.Located {
+		return path
+	}
+
+	return fmt.Sprintf("%s:%d:%d", path, issue.Range.Start.Line, issue.Range.Start.Column)
+}
+
+// NewJUnitTestSuites maps every document to a test suite and every
+// issue to a test case. Required issues are reported as errors, all
+// others as failures, with the severity as their type. Documents
+// without issues get a single passing test case.
+func NewJUnitTestSuites(docs []*CheckedDocument) *JUnitTestSuites {
+	suites := &JUnitTestSuites{Name: "Acrolinx"}
+
+	for _, doc := range sortedDocuments(docs) {
+		path := doc.slashPath()
+		suite := &JUnitTestSuite{Name: path}
+
+		for _, issue := range doc.locatedIssues() {
+			location := issueLocation(path, issue)
+			title := issueTitle(issue.Issue)
+
+			text := location + ": " + title
+			if guidance := htmlToText(issue.GuidanceHTML); guidance != "" {
+				text += "\n\n" + guidance
+			}
+
+			severity := doc.severity(issue.Issue)
+			result := &JUnitFailure{Type: severity, Message: title, Text: text}
+			testCase := &JUnitTestCase{Name: issueRuleID(issue.Issue), ClassName: path}
+			if issue.Located {
+				testCase.Name = fmt.Sprintf("%d:%d %s", issue.Range.Start.Line, issue.Range.Start.Column, testCase.Name)
+			}
+
+			if severity == SeverityError {
+				testCase.Error = result
+				suite.Errors++
+			} else {
+				testCase.Failure = result
+				suite.Failures++
+			}
+
+			suite.TestCases = append(suite.TestCases, testCase)
+		}
+
+		if len(suite.TestCases) == 0 {
+			suite.TestCases = append(suite.TestCases, &JUnitTestCase{Name: "Acrolinx check", ClassName: path})
+		}
+
+		suite.Tests = len(suite.TestCases)
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Errors += suite.Errors
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	return suites
+}
+
+// WriteJUnit writes the JUnit XML report for docs to w.
+func WriteJUnit(w io.Writer, docs []*CheckedDocument) error {
+	return writeXML(w, NewJUnitTestSuites(docs))
+}
+
+func writeXML(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("Error writing XML: %w", err)
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("Error writing XML: %w", err)
+	}
+
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return fmt.Errorf("Error writing XML: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package acrolinx
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteJUnit(t *testing.T) {
+	docs := reportTestDocuments()
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteJUnit(&buf, docs))
+	assertGolden(t, "report.junit.xml", buf.String())
+
+	// The output doesn't depend on the order of the documents.
+	var reversed bytes.Buffer
+	assert.NoError(t, WriteJUnit(&reversed, []*CheckedDocument{docs[1], docs[0]}))
+	assert.Equal(t, buf.String(), reversed.String())
+}
+
+func TestNewJUnitTestSuites(t *testing.T) {
+	suites := NewJUnitTestSuites(reportTestDocuments())
+
+	assert.Equal(t, 5, suites.Tests)
+	assert.Equal(t, 1, suites.Failures)
+	assert.Equal(t, 3, suites.Errors)
+
+	empty := suites.Suites[0]
+	assert.Equal(t, "docs/empty.md", empty.Name)
+	assert.Len(t, empty.TestCases, 1)
+	assert.Nil(t, empty.TestCases[0].Failure)
+	assert.Nil(t, empty.TestCases[0].Error)
+
+	guide := suites.Suites[1]
+	assert.Equal(t, "3:1 use_comma_after_introductory_phrase", guide.TestCases[1].Name)
+	assert.Equal(t, "Could you add a comma after the introductory phrase?", guide.TestCases[1].Error.Message)
+	assert.Equal(t, SeverityError, guide.TestCases[1].Error.Type)
+	assert.Equal(t, "4:7 contraction", guide.TestCases[3].Name)
+	if assert.NotNil(t, guide.TestCases[3].Failure) {
+		assert.Equal(t, SeverityWarning, guide.TestCases[3].Failure.Type)
+	}
+}
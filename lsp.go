@@ -0,0 +1,827 @@
+package acrolinx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// ErrExitWithoutShutdown is returned by LanguageServer.Serve if the
+// client sent the exit notification without a shutdown request first.
+// The Language Server Protocol asks the server to exit with code 1 then.
+var ErrExitWithoutShutdown = errors.New("exit without shutdown request")
+
+// LSPAddToDictionaryCommand is the command of the code actions adding
+// words to the dictionary. Its arguments are the document URI and the
+// word.
+const LSPAddToDictionaryCommand = "acrolinx.addToDictionary"
+
+const (
+	lspSeverityError       = 1
+	lspSeverityWarning     = 2
+	lspSeverityInformation = 3
+
+	lspMessageError = 1
+
+	lspTextDocumentSyncFull = 1
+)
+
+type LanguageServerOptions struct {
+	// CheckOptions are the options every document is checked with.
+	CheckOptions *CheckOptions
+	// Formats resolves the content format of documents whose
+	// CheckOptions don't set one. Defaults to a resolver without
+	// capabilities.
+	Formats *ContentFormatResolver
+	// Dictionary enables code actions adding words to the dictionary
+	// for issues with CanAddToDictionary set.
+	Dictionary Dictionary
+	// DictionaryScope is the scope of the dictionary words are added to.
+	// Defaults to the platform default.
+	DictionaryScope string
+}
+
+// LanguageServer publishes the issues found by the platform as
+// diagnostics to editors supporting the Language Server Protocol.
+// Documents are checked when they are opened and saved. Suggestions
+// are offered as code actions and guidance is shown on hover.
+type LanguageServer struct {
+	checker Checker
+	opts    LanguageServerOptions
+	conn    *jsonrpcConn
+	ctx     context.Context
+	cancel  context.CancelFunc
+
+	mu          sync.Mutex
+	docs        map[string]*lspDocument
+	initialized bool
+	shutdown    bool
+	// writeErr is the first error writing a message from a goroutine.
+	// Serve returns it.
+	writeErr error
+	wg       sync.WaitGroup
+}
+
+// lspAsyncRequests are the requests calling the platform. They are
+// handled concurrently, so that they don't hold up other messages.
+var lspAsyncRequests = map[string]bool{
+	"workspace/executeCommand": true,
+}
+
+// lspDocument is an open document.
+type lspDocument struct {
+	uri     string
+	version int
+	content string
+	// checked is the last completed check of the document.
+	checked *CheckedDocument
+	// cancel cancels the check in flight, and gen counts the checks
+	// started, so that results of superseded checks are dropped.
+	cancel context.CancelFunc
+	gen    int
+}
+
+func NewLanguageServer(checker Checker, opts *LanguageServerOptions) *LanguageServer {
+	s := &LanguageServer{
+		checker: checker,
+		docs:    make(map[string]*lspDocument),
+	}
+	if opts != nil {
+		s.opts = *opts
+	}
+	if s.opts.Formats == nil {
+		s.opts.Formats, _ = NewContentFormatResolver(nil)
+	}
+
+	return s
+}
+
+// Serve reads requests from r and writes responses to w until the
+// client sends the exit notification or closes r. Checks still running
+// are then cancelled. Notifications other than exit are ignored before
+// the initialize request and after the shutdown request, and requests
+// after shutdown fail.
+func (s *LanguageServer) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer func() {
+		cancel()
+		s.wg.Wait()
+	}()
+
+	s.ctx, s.cancel = ctx, cancel
+	s.conn = newJSONRPCConn(r, w)
+
+	for {
+		msg, err := s.conn.read()
+		if err == io.EOF {
+			return s.failed()
+		}
+		if err := s.failed(); err != nil {
+			return err
+		}
+
+		var rpcErr *jsonrpcError
+		if errors.As(err, &rpcErr) {
+			if err := s.conn.reply(json.RawMessage("null"), nil, rpcErr); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		s.mu.Lock()
+		initialized, shutdown := s.initialized, s.shutdown
+		s.mu.Unlock()
+
+		if msg.Method == "exit" {
+			if !shutdown {
+				return ErrExitWithoutShutdown
+			}
+			return nil
+		}
+
+		if msg.ID == nil {
+			if initialized && !shutdown {
+				s.handleNotification(msg.Method, msg.Params)
+			}
+			continue
+		}
+
+		if lspAsyncRequests[msg.Method] {
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				result, err := s.handleRequest(msg.Method, msg.Params)
+				s.reply(msg.ID, result, err)
+			}()
+			continue
+		}
+
+		result, err := s.handleRequest(msg.Method, msg.Params)
+		if err := s.conn.reply(msg.ID, result, err); err != nil {
+			return err
+		}
+	}
+}
+
+// notify sends a notification from a goroutine. Errors are returned by
+// Serve.
+func (s *LanguageServer) notify(method string, params interface{}) {
+	s.fail(s.conn.notify(method, params))
+}
+
+// reply answers a request from a goroutine. Errors are returned by
+// Serve.
+func (s *LanguageServer) reply(id json.RawMessage, result interface{}, err error) {
+	s.fail(s.conn.reply(id, result, err))
+}
+
+// fail records the first error writing a message and stops the server.
+func (s *LanguageServer) fail(err error) {
+	if err == nil {
+		return
+	}
+
+	s.mu.Lock()
+	if s.writeErr == nil {
+		s.writeErr = err
+	}
+	s.mu.Unlock()
+	s.cancel()
+}
+
+func (s *LanguageServer) failed() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.writeErr
+}
+
+func (s *LanguageServer) handleRequest(method string, params json.RawMessage) (interface{}, error) {
+	s.mu.Lock()
+	initialized, shutdown := s.initialized, s.shutdown
+	s.mu.Unlock()
+	switch {
+	case shutdown:
+		return nil, &jsonrpcError{jsonrpcInvalidRequest, "Server is shutting down"}
+	case !initialized && method != "initialize":
+		return nil, &jsonrpcError{lspServerNotInitialized, "Server not initialized"}
+	case initialized && method == "initialize":
+		return nil, &jsonrpcError{jsonrpcInvalidRequest, "Server already initialized"}
+	}
+
+	switch method {
+	case "initialize":
+		return s.initialize()
+	case "shutdown":
+		s.mu.Lock()
+		s.shutdown = true
+		s.mu.Unlock()
+		return nil, nil
+	case "textDocument/codeAction":
+		var p lspCodeActionParams
+		if err := unmarshalParams(params, &p); err != nil {
+			return nil, err
+		}
+		return s.codeActions(&p), nil
+	case "textDocument/hover":
+		var p lspTextDocumentPositionParams
+		if err := unmarshalParams(params, &p); err != nil {
+			return nil, err
+		}
+		return s.hover(&p), nil
+	case "workspace/executeCommand":
+		var p lspExecuteCommandParams
+		if err := unmarshalParams(params, &p); err != nil {
+			return nil, err
+		}
+		return nil, s.executeCommand(&p)
+	}
+
+	return nil, &jsonrpcError{jsonrpcMethodNotFound, fmt.Sprintf("Method not found: %s", method)}
+}
+
+func unmarshalParams(params json.RawMessage, v interface{}) error {
+	if err := json.Unmarshal(params, v); err != nil {
+		return &jsonrpcError{jsonrpcInvalidParams, fmt.Sprintf("Invalid params: %v", err)}
+	}
+
+	return nil
+}
+
+func (s *LanguageServer) handleNotification(method string, params json.RawMessage) {
+	switch method {
+	case "textDocument/didOpen":
+		var p lspDidOpenParams
+		if json.Unmarshal(params, &p) == nil {
+			s.open(p.TextDocument)
+		}
+	case "textDocument/didChange":
+		var p lspDidChangeParams
+		if json.Unmarshal(params, &p) == nil {
+			s.change(&p)
+		}
+	case "textDocument/didSave":
+		var p lspDidSaveParams
+		if json.Unmarshal(params, &p) == nil {
+			s.save(&p)
+		}
+	case "textDocument/didClose":
+		var p lspDidCloseParams
+		if json.Unmarshal(params, &p) == nil {
+			s.close(p.TextDocument.URI)
+		}
+	}
+}
+
+func (s *LanguageServer) initialize() (*lspInitializeResult, error) {
+	s.mu.Lock()
+	s.initialized = true
+	s.mu.Unlock()
+
+	result := &lspInitializeResult{
+		Capabilities: lspServerCapabilities{
+			TextDocumentSync: &lspTextDocumentSyncOptions{
+				OpenClose: true,
+				Change:    lspTextDocumentSyncFull,
+				Save:      &lspSaveOptions{IncludeText: true},
+			},
+			HoverProvider:      true,
+			CodeActionProvider: &lspCodeActionOptions{CodeActionKinds: []string{"quickfix"}},
+		},
+		ServerInfo: &lspServerInfo{Name: "acrolinx"},
+	}
+
+	if s.opts.Dictionary != nil {
+		result.Capabilities.ExecuteCommandProvider = &lspExecuteCommandOptions{
+			Commands: []string{LSPAddToDictionaryCommand},
+		}
+	}
+
+	return result, nil
+}
+
+func (s *LanguageServer) open(item lspTextDocumentItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc := &lspDocument{uri: item.URI, version: item.Version, content: item.Text}
+	s.docs[item.URI] = doc
+	s.check(doc)
+}
+
+func (s *LanguageServer) change(p *lspDidChangeParams) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc, ok := s.docs[p.TextDocument.URI]
+	if !ok || len(p.ContentChanges) == 0 {
+		return
+	}
+
+	doc.version = p.TextDocument.Version
+	doc.content = p.ContentChanges[len(p.ContentChanges)-1].Text
+}
+
+func (s *LanguageServer) save(p *lspDidSaveParams) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc, ok := s.docs[p.TextDocument.URI]
+	if !ok {
+		return
+	}
+
+	if p.Text != nil {
+		doc.content = *p.Text
+	}
+	s.check(doc)
+}
+
+func (s *LanguageServer) close(uri string) {
+	s.mu.Lock()
+	doc, ok := s.docs[uri]
+	if ok {
+		if doc.cancel != nil {
+			doc.cancel()
+		}
+		delete(s.docs, uri)
+	}
+	s.mu.Unlock()
+
+	if ok {
+		s.notify("textDocument/publishDiagnostics", &lspPublishDiagnosticsParams{URI: uri, Diagnostics: []*lspDiagnostic{}})
+	}
+}
+
+// check starts checking the content of doc, cancelling the check in
+// flight. It must be called with s.mu held.
+func (s *LanguageServer) check(doc *lspDocument) {
+	if doc.cancel != nil {
+		doc.cancel()
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	doc.cancel = cancel
+	doc.gen++
+	gen, version, content := doc.gen, doc.version, doc.content
+	opts := s.submitOptions(doc.uri, content)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer cancel()
+
+		result, err := s.checker.Check(ctx, opts)
+
+		s.mu.Lock()
+		current := s.docs[doc.uri] == doc && doc.gen == gen && ctx.Err() == nil
+		if current {
+			doc.cancel = nil
+			if err == nil {
+				doc.checked = &CheckedDocument{Path: doc.uri, Content: content, Result: result}
+			}
+		}
+		checked := doc.checked
+		s.mu.Unlock()
+
+		if !current {
+			return
+		}
+
+		if err != nil {
+			s.notify("window/logMessage", &lspLogMessageParams{
+				Type:    lspMessageError,
+				Message: fmt.Sprintf("Error checking %s: %v", doc.uri, err),
+			})
+			return
+		}
+
+		s.notify("textDocument/publishDiagnostics", &lspPublishDiagnosticsParams{
+			URI:         doc.uri,
+			Version:     version,
+			Diagnostics: lspDiagnostics(checked),
+		})
+	}()
+}
+
+// submitOptions returns the options to check the document at uri with.
+func (s *LanguageServer) submitOptions(uri string, content string) *SubmitCheckOptions {
+	checkOpts := &CheckOptions{}
+	if s.opts.CheckOptions != nil {
+		*checkOpts = *s.opts.CheckOptions
+	}
+
+	opts := &SubmitCheckOptions{Content: content, CheckOptions: checkOpts}
+	if checkOpts.ContentFormat == "" {
+		s.opts.Formats.Apply(opts, lspURIPath(uri), []byte(content))
+	}
+	opts.Document = &Document{Reference: uri}
+
+	return opts
+}
+
+// lspURIPath returns the path of a document URI, used to detect its
+// content format.
+func lspURIPath(uri string) string {
+	if u, err := url.Parse(uri); err == nil && u.Path != "" {
+		return u.Path
+	}
+
+	return uri
+}
+
+func lspDiagnostics(doc *CheckedDocument) []*lspDiagnostic {
+	diagnostics := []*lspDiagnostic{}
+	x := NewPositionIndex(doc.Content)
+	for _, issue := range doc.Result.Issues {
+		diagnostics = append(diagnostics, lspIssueDiagnostic(doc, x, issue))
+	}
+
+	return diagnostics
+}
+
+func lspIssueDiagnostic(doc *CheckedDocument, x *PositionIndex, issue *Issue) *lspDiagnostic {
+	severity := lspSeverityInformation
+	switch doc.severity(issue) {
+	case SeverityError:
+		severity = lspSeverityError
+	case SeverityWarning:
+		severity = lspSeverityWarning
+	}
+
+	r, message := lspDocumentRange, issueTitle(issue)+" (whole document)"
+	if issueRange, ok := x.IssueRange(issue); ok {
+		r, message = lspRangeOf(issueRange), issueTitle(issue)
+	}
+
+	return &lspDiagnostic{
+		Range:    r,
+		Severity: severity,
+		Code:     issueRuleID(issue),
+		Source:   "Acrolinx",
+		Message:  message,
+	}
+}
+
+// lspDocumentRange is the range of diagnostics for issues without
+// matches, which concern the whole document, like its length.
+// Diagnostics need a range, so they get an empty one at the start of
+// the document, and their message marks them as concerning the whole
+// document.
+var lspDocumentRange = lspRange{Start: lspPosition{Line: 0, Character: 0}, End: lspPosition{Line: 0, Character: 0}}
+
+func lspRangeOf(r Range) lspRange {
+	return lspRange{
+		Start: lspPosition{Line: r.Start.Line - 1, Character: r.Start.UTF16Column - 1},
+		End:   lspPosition{Line: r.End.Line - 1, Character: r.End.UTF16Column - 1},
+	}
+}
+
+// lspOffset returns the UTF-16 offset of an LSP position, whose
+// character counts UTF-16 code units as well.
+func lspOffset(x *PositionIndex, p lspPosition) int {
+	if p.Line < 0 {
+		return 0
+	}
+	if p.Line >= len(x.lines) {
+		return x.Len()
+	}
+
+	end := x.Len()
+	if p.Line+1 < len(x.lines) {
+		end = x.lines[p.Line+1].utf16
+	}
+
+	return min(x.lines[p.Line].utf16+max(p.Character, 0), end)
+}
+
+// issuesAt returns the issues of the last check of the document at uri
+// whose range overlaps the offsets begin to end, along with the check.
+func (s *LanguageServer) issuesAt(uri string, begin func(*PositionIndex) int, end func(*PositionIndex) int) (*lspDocument, *CheckedDocument, []*Issue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc, ok := s.docs[uri]
+	if !ok || doc.checked == nil {
+		return nil, nil, nil
+	}
+
+	checked := doc.checked
+	x := NewPositionIndex(checked.Content)
+	b, e := begin(x), end(x)
+
+	var issues []*Issue
+	for _, issue := range checked.Result.Issues {
+		if r, ok := x.IssueRange(issue); ok && r.Start.UTF16 <= e && r.End.UTF16 >= b {
+			issues = append(issues, issue)
+		}
+	}
+
+	return doc, checked, issues
+}
+
+func (s *LanguageServer) codeActions(p *lspCodeActionParams) []*lspCodeAction {
+	actions := []*lspCodeAction{}
+
+	doc, checked, issues := s.issuesAt(p.TextDocument.URI,
+		func(x *PositionIndex) int { return lspOffset(x, p.Range.Start) },
+		func(x *PositionIndex) int { return lspOffset(x, p.Range.End) })
+	if checked == nil {
+		return actions
+	}
+
+	s.mu.Lock()
+	stale := doc.content != checked.Content
+	s.mu.Unlock()
+
+	x := NewPositionIndex(checked.Content)
+	for _, issue := range issues {
+		diagnostic := lspIssueDiagnostic(checked, x, issue)
+
+		// Edits would apply to outdated offsets after the document
+		// changed.
+		if !stale {
+			for _, suggestion := range issue.Suggestions {
+				edits, err := fixEdits(x, checked.Content, &Fix{issue, suggestion})
+				if err != nil {
+					continue
+				}
+
+				textEdits := make([]*lspTextEdit, len(edits))
+				for i, e := range edits {
+					textEdits[i] = &lspTextEdit{
+						Range:   lspRangeOf(Range{x.FromUTF16(e.Begin), x.FromUTF16(e.End)}),
+						NewText: e.Replacement,
+					}
+				}
+
+				actions = append(actions, &lspCodeAction{
+					Title:       fmt.Sprintf("Replace with %q", lspSuggestionText(suggestion)),
+					Kind:        "quickfix",
+					Diagnostics: []*lspDiagnostic{diagnostic},
+					Edit:        &lspWorkspaceEdit{Changes: map[string][]*lspTextEdit{p.TextDocument.URI: textEdits}},
+				})
+			}
+		}
+
+		if issue.CanAddToDictionary && s.opts.Dictionary != nil {
+			surface := lspIssueSurface(issue)
+			title := fmt.Sprintf("Add %q to dictionary", surface)
+			actions = append(actions, &lspCodeAction{
+				Title:       title,
+				Kind:        "quickfix",
+				Diagnostics: []*lspDiagnostic{diagnostic},
+				Command: &lspCommand{
+					Title:     title,
+					Command:   LSPAddToDictionaryCommand,
+					Arguments: []interface{}{p.TextDocument.URI, surface},
+				},
+			})
+		}
+	}
+
+	return actions
+}
+
+func lspSuggestionText(suggestion *Suggestion) string {
+	if suggestion.Surface != "" {
+		return suggestion.Surface
+	}
+
+	return strings.Join(suggestion.Replacements, " ")
+}
+
+// lspIssueSurface returns the word an issue is about.
+func lspIssueSurface(issue *Issue) string {
+	if issue.DisplaySurface != "" {
+		return issue.DisplaySurface
+	}
+
+	var parts []string
+	if info := issue.PositionalInformation; info != nil {
+		for _, m := range info.Matches {
+			parts = append(parts, m.OriginalPart)
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func (s *LanguageServer) hover(p *lspTextDocumentPositionParams) *lspHover {
+	offset := func(x *PositionIndex) int { return lspOffset(x, p.Position) }
+	_, checked, issues := s.issuesAt(p.TextDocument.URI, offset, offset)
+	if len(issues) == 0 {
+		return nil
+	}
+
+	x := NewPositionIndex(checked.Content)
+	var parts []string
+	var hoverRange *lspRange
+	for _, issue := range issues {
+		text := issueTitle(issue)
+		if guidance := htmlToText(issue.GuidanceHTML); guidance != "" {
+			text += "\n\n" + guidance
+		}
+		parts = append(parts, text)
+
+		if hoverRange == nil {
+			r, _ := x.IssueRange(issue)
+			lr := lspRangeOf(r)
+			hoverRange = &lr
+		}
+	}
+
+	return &lspHover{
+		Contents: lspMarkupContent{Kind: "plaintext", Value: strings.Join(parts, "\n\n---\n\n")},
+		Range:    hoverRange,
+	}
+}
+
+func (s *LanguageServer) executeCommand(p *lspExecuteCommandParams) error {
+	if p.Command != LSPAddToDictionaryCommand || s.opts.Dictionary == nil {
+		return &jsonrpcError{jsonrpcInvalidParams, fmt.Sprintf("Unknown command: %s", p.Command)}
+	}
+
+	var uri, surface string
+	if len(p.Arguments) != 2 || json.Unmarshal(p.Arguments[0], &uri) != nil || json.Unmarshal(p.Arguments[1], &surface) != nil {
+		return &jsonrpcError{jsonrpcInvalidParams, "Invalid params: expected document URI and word"}
+	}
+
+	opts := &AddToDictionaryOptions{Surface: surface, Scope: s.opts.DictionaryScope}
+	if s.opts.CheckOptions != nil {
+		opts.GuidanceProfileID = s.opts.CheckOptions.GuidanceProfileID
+	}
+	if _, _, err := s.opts.Dictionary.AddToDictionary(s.ctx, opts); err != nil {
+		return fmt.Errorf("Error adding %q to dictionary: %w", surface, err)
+	}
+
+	// Check again to remove the issues of the word.
+	s.mu.Lock()
+	if doc, ok := s.docs[uri]; ok {
+		s.check(doc)
+	}
+	s.mu.Unlock()
+
+	return nil
+}
+
+// The types below are the parts of the Language Server Protocol used
+// by the server.
+
+type lspPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lspRange struct {
+	Start lspPosition `json:"start"`
+	End   lspPosition `json:"end"`
+}
+
+type lspTextDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+type lspVersionedTextDocumentIdentifier struct {
+	URI     string `json:"uri"`
+	Version int    `json:"version"`
+}
+
+type lspTextDocumentItem struct {
+	URI        string `json:"uri"`
+	LanguageID string `json:"languageId"`
+	Version    int    `json:"version"`
+	Text       string `json:"text"`
+}
+
+type lspDidOpenParams struct {
+	TextDocument lspTextDocumentItem `json:"textDocument"`
+}
+
+type lspDidChangeParams struct {
+	TextDocument   lspVersionedTextDocumentIdentifier `json:"textDocument"`
+	ContentChanges []*lspContentChange                `json:"contentChanges"`
+}
+
+// lspContentChange is the full text of a changed document, as the
+// server only supports full synchronization.
+type lspContentChange struct {
+	Text string `json:"text"`
+}
+
+type lspDidSaveParams struct {
+	TextDocument lspTextDocumentIdentifier `json:"textDocument"`
+	Text         *string                   `json:"text"`
+}
+
+type lspDidCloseParams struct {
+	TextDocument lspTextDocumentIdentifier `json:"textDocument"`
+}
+
+type lspTextDocumentPositionParams struct {
+	TextDocument lspTextDocumentIdentifier `json:"textDocument"`
+	Position     lspPosition               `json:"position"`
+}
+
+type lspCodeActionParams struct {
+	TextDocument lspTextDocumentIdentifier `json:"textDocument"`
+	Range        lspRange                  `json:"range"`
+}
+
+type lspExecuteCommandParams struct {
+	Command   string            `json:"command"`
+	Arguments []json.RawMessage `json:"arguments"`
+}
+
+type lspInitializeResult struct {
+	Capabilities lspServerCapabilities `json:"capabilities"`
+	ServerInfo   *lspServerInfo        `json:"serverInfo,omitempty"`
+}
+
+type lspServerCapabilities struct {
+	TextDocumentSync       *lspTextDocumentSyncOptions `json:"textDocumentSync,omitempty"`
+	HoverProvider          bool                        `json:"hoverProvider"`
+	CodeActionProvider     *lspCodeActionOptions       `json:"codeActionProvider,omitempty"`
+	ExecuteCommandProvider *lspExecuteCommandOptions   `json:"executeCommandProvider,omitempty"`
+}
+
+type lspTextDocumentSyncOptions struct {
+	OpenClose bool            `json:"openClose"`
+	Change    int             `json:"change"`
+	Save      *lspSaveOptions `json:"save,omitempty"`
+}
+
+type lspSaveOptions struct {
+	IncludeText bool `json:"includeText"`
+}
+
+type lspCodeActionOptions struct {
+	CodeActionKinds []string `json:"codeActionKinds"`
+}
+
+type lspExecuteCommandOptions struct {
+	Commands []string `json:"commands"`
+}
+
+type lspServerInfo struct {
+	Name string `json:"name"`
+}
+
+type lspDiagnostic struct {
+	Range    lspRange `json:"range"`
+	Severity int      `json:"severity"`
+	Code     string   `json:"code,omitempty"`
+	Source   string   `json:"source"`
+	Message  string   `json:"message"`
+}
+
+type lspPublishDiagnosticsParams struct {
+	URI         string           `json:"uri"`
+	Version     int              `json:"version,omitempty"`
+	Diagnostics []*lspDiagnostic `json:"diagnostics"`
+}
+
+type lspLogMessageParams struct {
+	Type    int    `json:"type"`
+	Message string `json:"message"`
+}
+
+type lspTextEdit struct {
+	Range   lspRange `json:"range"`
+	NewText string   `json:"newText"`
+}
+
+type lspWorkspaceEdit struct {
+	Changes map[string][]*lspTextEdit `json:"changes"`
+}
+
+type lspCommand struct {
+	Title     string        `json:"title"`
+	Command   string        `json:"command"`
+	Arguments []interface{} `json:"arguments,omitempty"`
+}
+
+type lspCodeAction struct {
+	Title       string            `json:"title"`
+	Kind        string            `json:"kind"`
+	Diagnostics []*lspDiagnostic  `json:"diagnostics,omitempty"`
+	Edit        *lspWorkspaceEdit `json:"edit,omitempty"`
+	Command     *lspCommand       `json:"command,omitempty"`
+}
+
+type lspMarkupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+type lspHover struct {
+	Contents lspMarkupContent `json:"contents"`
+	Range    *lspRange        `json:"range,omitempty"`
+}
@@ -0,0 +1,468 @@
+package acrolinx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// lspChecker flags "utilise", suggesting "use", and the misspelling
+// "teh", unless it has been added to the dictionary. Content containing
+// "fail" can't be checked.
+type lspChecker struct {
+	mu    sync.Mutex
+	words map[string]bool
+	opts  []*SubmitCheckOptions
+}
+
+func (c *lspChecker) Check(ctx context.Context, opts *SubmitCheckOptions) (*CheckResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.opts = append(c.opts, opts)
+
+	if strings.Contains(opts.Content, "fail") {
+		return nil, errors.New("check failed")
+	}
+
+	result := &CheckResult{Quality: &Quality{Score: 100}}
+	if i := strings.Index(opts.Content, "utilise"); i >= 0 {
+		result.Issues = append(result.Issues, &Issue{
+			GoalID:          "clarity",
+			InternalName:    "simpler_words",
+			DisplayNameHTML: "Use a simpler word",
+			GuidanceHTML:    "<p>Prefer <b>use</b>.</p>",
+			Scoring:         "required",
+			PositionalInformation: &PositionalInformation{
+				Matches: []*Match{{OriginalPart: "utilise", OriginalBegin: i, OriginalEnd: i + 7}},
+			},
+			Suggestions: []*Suggestion{{Surface: "use", Replacements: []string{"use"}}},
+		})
+	}
+	if i := strings.Index(opts.Content, "teh"); i >= 0 && !c.words["teh"] {
+		result.Issues = append(result.Issues, &Issue{
+			GoalID:             "spelling",
+			InternalName:       "spelling",
+			DisplaySurface:     "teh",
+			CanAddToDictionary: true,
+			PositionalInformation: &PositionalInformation{
+				Matches: []*Match{{OriginalPart: "teh", OriginalBegin: i, OriginalEnd: i + 3}},
+			},
+		})
+	}
+
+	return result, nil
+}
+
+type lspDictionary struct {
+	checker *lspChecker
+	added   []*AddToDictionaryOptions
+}
+
+// AddToDictionary blocks until ctx is done for the word "slow".
+func (d *lspDictionary) AddToDictionary(ctx context.Context, opts *AddToDictionaryOptions) (*DictionaryEntry, Links, error) {
+	if opts.Surface == "slow" {
+		<-ctx.Done()
+		return nil, nil, ctx.Err()
+	}
+
+	d.checker.mu.Lock()
+	defer d.checker.mu.Unlock()
+	d.added = append(d.added, opts)
+	d.checker.words[opts.Surface] = true
+	return &DictionaryEntry{Surface: opts.Surface, Scope: opts.Scope}, nil, nil
+}
+
+// lspClient talks to a language server over pipes.
+type lspClient struct {
+	t             *testing.T
+	conn          *jsonrpcConn
+	in            *io.PipeWriter
+	id            int
+	responses     chan *jsonrpcMessage
+	notifications chan *jsonrpcMessage
+	done          chan error
+}
+
+func startLanguageServer(t *testing.T, s *LanguageServer) *lspClient {
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+
+	c := &lspClient{
+		t:             t,
+		conn:          newJSONRPCConn(clientIn, clientOut),
+		in:            clientOut,
+		responses:     make(chan *jsonrpcMessage, 10),
+		notifications: make(chan *jsonrpcMessage, 10),
+		done:          make(chan error, 1),
+	}
+
+	go func() {
+		c.done <- s.Serve(context.Background(), serverIn, serverOut)
+		serverOut.Close()
+	}()
+
+	go func() {
+		for {
+			msg, err := c.conn.read()
+			if err != nil {
+				return
+			}
+			if msg.ID != nil {
+				c.responses <- msg
+			} else {
+				c.notifications <- msg
+			}
+		}
+	}()
+
+	return c
+}
+
+func (c *lspClient) request(method string, params interface{}, result interface{}) *jsonrpcError {
+	c.t.Helper()
+
+	c.id++
+	data, err := json.Marshal(params)
+	assert.NoError(c.t, err)
+	id := json.RawMessage(strconv.Itoa(c.id))
+	assert.NoError(c.t, c.conn.write(&jsonrpcMessage{ID: id, Method: method, Params: data}))
+
+	select {
+	case msg := <-c.responses:
+		assert.Equal(c.t, string(id), string(msg.ID))
+		if msg.Error != nil {
+			return msg.Error
+		}
+		if result != nil {
+			assert.NoError(c.t, json.Unmarshal(msg.Result, result))
+		}
+	case <-time.After(5 * time.Second):
+		c.t.Fatalf("no response to %s", method)
+	}
+
+	return nil
+}
+
+func (c *lspClient) notify(method string, params interface{}) {
+	c.t.Helper()
+	assert.NoError(c.t, c.conn.notify(method, params))
+}
+
+func (c *lspClient) next(method string, params interface{}) {
+	c.t.Helper()
+
+	select {
+	case msg := <-c.notifications:
+		assert.Equal(c.t, method, msg.Method)
+		assert.NoError(c.t, json.Unmarshal(msg.Params, params))
+	case <-time.After(5 * time.Second):
+		c.t.Fatalf("no %s notification", method)
+	}
+}
+
+// exit sends the exit notification and returns the error of Serve.
+func (c *lspClient) exit() error {
+	c.t.Helper()
+	c.notify("exit", nil)
+	defer c.in.Close()
+
+	select {
+	case err := <-c.done:
+		return err
+	case <-time.After(5 * time.Second):
+		c.t.Fatal("server didn't exit")
+		return nil
+	}
+}
+
+func TestLanguageServer(t *testing.T) {
+	checker := &lspChecker{words: map[string]bool{}}
+	dictionary := &lspDictionary{checker: checker}
+	c := startLanguageServer(t, NewLanguageServer(checker, &LanguageServerOptions{
+		CheckOptions:    &CheckOptions{GuidanceProfileID: "en"},
+		Dictionary:      dictionary,
+		DictionaryScope: "guidanceProfile",
+	}))
+
+	var init lspInitializeResult
+	assert.Nil(t, c.request("initialize", map[string]interface{}{}, &init))
+	assert.True(t, init.Capabilities.HoverProvider)
+	assert.Equal(t, lspTextDocumentSyncFull, init.Capabilities.TextDocumentSync.Change)
+	assert.Equal(t, []string{LSPAddToDictionaryCommand}, init.Capabilities.ExecuteCommandProvider.Commands)
+
+	const uri = "file:///docs/readme.md"
+	c.notify("textDocument/didOpen", &lspDidOpenParams{TextDocument: lspTextDocumentItem{
+		URI: uri, LanguageID: "markdown", Version: 1, Text: "# Title\n\nPlease utilise teh tool.\n",
+	}})
+
+	var published lspPublishDiagnosticsParams
+	c.next("textDocument/publishDiagnostics", &published)
+	assert.Equal(t, uri, published.URI)
+	assert.Equal(t, 1, published.Version)
+	assert.Equal(t, []*lspDiagnostic{
+		{
+			Range:    lspRange{lspPosition{2, 7}, lspPosition{2, 14}},
+			Severity: lspSeverityError,
+			Code:     "simpler_words",
+			Source:   "Acrolinx",
+			Message:  "Use a simpler word",
+		},
+		{
+			Range:    lspRange{lspPosition{2, 15}, lspPosition{2, 18}},
+			Severity: lspSeverityInformation,
+			Code:     "spelling",
+			Source:   "Acrolinx",
+			Message:  "teh",
+		},
+	}, published.Diagnostics)
+	assert.Equal(t, "en", checker.opts[0].CheckOptions.GuidanceProfileID)
+	assert.Equal(t, ContentFormatMarkdown, checker.opts[0].CheckOptions.ContentFormat)
+
+	var hover lspHover
+	assert.Nil(t, c.request("textDocument/hover", &lspTextDocumentPositionParams{
+		TextDocument: lspTextDocumentIdentifier{URI: uri},
+		Position:     lspPosition{2, 9},
+	}, &hover))
+	assert.Equal(t, "plaintext", hover.Contents.Kind)
+	assert.Equal(t, "Use a simpler word\n\nPrefer use.", hover.Contents.Value)
+
+	var actions []*lspCodeAction
+	assert.Nil(t, c.request("textDocument/codeAction", &lspCodeActionParams{
+		TextDocument: lspTextDocumentIdentifier{URI: uri},
+		Range:        lspRange{lspPosition{2, 0}, lspPosition{2, 25}},
+	}, &actions))
+	if assert.Len(t, actions, 2) {
+		assert.Equal(t, `Replace with "use"`, actions[0].Title)
+		assert.Equal(t, map[string][]*lspTextEdit{uri: {{
+			Range:   lspRange{lspPosition{2, 7}, lspPosition{2, 14}},
+			NewText: "use",
+		}}}, actions[0].Edit.Changes)
+
+		assert.Equal(t, `Add "teh" to dictionary`, actions[1].Title)
+		assert.Equal(t, LSPAddToDictionaryCommand, actions[1].Command.Command)
+		assert.Equal(t, []interface{}{uri, "teh"}, actions[1].Command.Arguments)
+	}
+
+	// Adding the word checks the document again.
+	assert.Nil(t, c.request("workspace/executeCommand", &lspExecuteCommandParams{
+		Command:   LSPAddToDictionaryCommand,
+		Arguments: []json.RawMessage{json.RawMessage(strconv.Quote(uri)), json.RawMessage(`"teh"`)},
+	}, nil))
+	assert.Equal(t, []*AddToDictionaryOptions{{Surface: "teh", Scope: "guidanceProfile", GuidanceProfileID: "en"}}, dictionary.added)
+	c.next("textDocument/publishDiagnostics", &published)
+	assert.Len(t, published.Diagnostics, 1)
+
+	// Edits aren't offered for content changed since the check.
+	c.notify("textDocument/didChange", &lspDidChangeParams{
+		TextDocument:   lspVersionedTextDocumentIdentifier{URI: uri, Version: 2},
+		ContentChanges: []*lspContentChange{{Text: "Please utilise the tool.\n"}},
+	})
+	actions = nil
+	assert.Nil(t, c.request("textDocument/codeAction", &lspCodeActionParams{
+		TextDocument: lspTextDocumentIdentifier{URI: uri},
+		Range:        lspRange{lspPosition{2, 8}, lspPosition{2, 8}},
+	}, &actions))
+	assert.Empty(t, actions)
+
+	c.notify("textDocument/didSave", &lspDidSaveParams{TextDocument: lspTextDocumentIdentifier{URI: uri}})
+	c.next("textDocument/publishDiagnostics", &published)
+	assert.Equal(t, 2, published.Version)
+	if assert.Len(t, published.Diagnostics, 1) {
+		assert.Equal(t, lspRange{lspPosition{0, 7}, lspPosition{0, 14}}, published.Diagnostics[0].Range)
+	}
+
+	c.notify("textDocument/didClose", &lspDidCloseParams{TextDocument: lspTextDocumentIdentifier{URI: uri}})
+	c.next("textDocument/publishDiagnostics", &published)
+	assert.Empty(t, published.Diagnostics)
+
+	assert.Nil(t, c.request("shutdown", nil, nil))
+	assert.NoError(t, c.exit())
+}
+
+func TestLSPDiagnosticsWithoutMatches(t *testing.T) {
+	doc := &CheckedDocument{
+		Content: "Some text.\nMore text.\n",
+		Result: &CheckResult{Issues: []*Issue{
+			{GoalID: "length", InternalName: "document_too_long", DisplayNameHTML: "Document too long"},
+			{
+				GoalID:          "clarity",
+				InternalName:    "vague",
+				DisplayNameHTML: "Vague word",
+				PositionalInformation: &PositionalInformation{
+					Matches: []*Match{{OriginalPart: "More", OriginalBegin: 11, OriginalEnd: 15}},
+				},
+			},
+		}},
+	}
+
+	diagnostics := lspDiagnostics(doc)
+	assert.Len(t, diagnostics, 2)
+	assert.Equal(t, lspDocumentRange, diagnostics[0].Range)
+	assert.Equal(t, "Document too long (whole document)", diagnostics[0].Message)
+	assert.Equal(t, lspRange{Start: lspPosition{Line: 1, Character: 0}, End: lspPosition{Line: 1, Character: 4}}, diagnostics[1].Range)
+	assert.Equal(t, "Vague word", diagnostics[1].Message)
+}
+
+func TestLanguageServerErrors(t *testing.T) {
+	checker := &lspChecker{}
+	c := startLanguageServer(t, NewLanguageServer(checker, nil))
+
+	err := c.request("textDocument/hover", map[string]interface{}{}, nil)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, lspServerNotInitialized, err.Code)
+	}
+
+	// Notifications before initialize are dropped.
+	c.notify("textDocument/didOpen", &lspDidOpenParams{TextDocument: lspTextDocumentItem{
+		URI: "file:///early.txt", Version: 1, Text: "Too early.",
+	}})
+
+	var init lspInitializeResult
+	assert.Nil(t, c.request("initialize", map[string]interface{}{}, &init))
+	assert.Nil(t, init.Capabilities.ExecuteCommandProvider)
+
+	err = c.request("initialize", map[string]interface{}{}, nil)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, jsonrpcInvalidRequest, err.Code)
+	}
+
+	err = c.request("textDocument/definition", map[string]interface{}{}, nil)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, jsonrpcMethodNotFound, err.Code)
+	}
+
+	err = c.request("workspace/executeCommand", &lspExecuteCommandParams{Command: LSPAddToDictionaryCommand}, nil)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, jsonrpcInvalidParams, err.Code)
+	}
+
+	c.notify("textDocument/didOpen", &lspDidOpenParams{TextDocument: lspTextDocumentItem{
+		URI: "file:///a.txt", Version: 1, Text: "This will fail.",
+	}})
+	var logged lspLogMessageParams
+	c.next("window/logMessage", &logged)
+	assert.Equal(t, lspMessageError, logged.Type)
+	assert.Equal(t, "Error checking file:///a.txt: check failed", logged.Message)
+
+	// Hovering outside issues returns null.
+	var hover *lspHover
+	assert.Nil(t, c.request("textDocument/hover", &lspTextDocumentPositionParams{
+		TextDocument: lspTextDocumentIdentifier{URI: "file:///a.txt"},
+	}, &hover))
+	assert.Nil(t, hover)
+
+	checker.mu.Lock()
+	assert.Len(t, checker.opts, 1)
+	checker.mu.Unlock()
+
+	assert.ErrorIs(t, c.exit(), ErrExitWithoutShutdown)
+}
+
+func TestLanguageServerShutdown(t *testing.T) {
+	c := startLanguageServer(t, NewLanguageServer(&lspChecker{}, nil))
+
+	assert.Nil(t, c.request("initialize", map[string]interface{}{}, nil))
+	assert.Nil(t, c.request("shutdown", nil, nil))
+
+	err := c.request("textDocument/hover", &lspTextDocumentPositionParams{}, nil)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, jsonrpcInvalidRequest, err.Code)
+	}
+
+	assert.NoError(t, c.exit())
+}
+
+func TestLanguageServerSlowCommand(t *testing.T) {
+	checker := &lspChecker{words: map[string]bool{}}
+	c := startLanguageServer(t, NewLanguageServer(checker, &LanguageServerOptions{
+		Dictionary: &lspDictionary{checker: checker},
+	}))
+	assert.Nil(t, c.request("initialize", map[string]interface{}{}, nil))
+
+	// Other requests are answered while the command is running.
+	params, err := json.Marshal(&lspExecuteCommandParams{
+		Command:   LSPAddToDictionaryCommand,
+		Arguments: []json.RawMessage{json.RawMessage(`"file:///a.txt"`), json.RawMessage(`"slow"`)},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, c.conn.write(&jsonrpcMessage{ID: json.RawMessage(`"command"`), Method: "workspace/executeCommand", Params: params}))
+
+	var hover *lspHover
+	assert.Nil(t, c.request("textDocument/hover", &lspTextDocumentPositionParams{
+		TextDocument: lspTextDocumentIdentifier{URI: "file:///a.txt"},
+	}, &hover))
+	assert.Nil(t, c.request("shutdown", nil, nil))
+
+	// Exiting cancels the command.
+	assert.NoError(t, c.exit())
+	msg := <-c.responses
+	assert.Equal(t, `"command"`, string(msg.ID))
+	if assert.NotNil(t, msg.Error) {
+		assert.Contains(t, msg.Error.Message, "context canceled")
+	}
+}
+
+// failingWriter fails writing notifications, and closes failed when it
+// does.
+type failingWriter struct {
+	w      io.Writer
+	once   sync.Once
+	failed chan struct{}
+}
+
+func (f *failingWriter) Write(p []byte) (int, error) {
+	if strings.Contains(string(p), "publishDiagnostics") {
+		f.once.Do(func() { close(f.failed) })
+		return 0, errors.New("broken pipe")
+	}
+	return f.w.Write(p)
+}
+
+func TestLanguageServerWriteError(t *testing.T) {
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+	defer clientOut.Close()
+	w := &failingWriter{w: serverOut, failed: make(chan struct{})}
+	c := &lspClient{t: t, conn: newJSONRPCConn(clientIn, clientOut), responses: make(chan *jsonrpcMessage, 10)}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- NewLanguageServer(&lspChecker{}, nil).Serve(context.Background(), serverIn, w)
+		serverIn.Close()
+	}()
+	go func() {
+		for {
+			msg, err := c.conn.read()
+			if err != nil {
+				return
+			}
+			c.responses <- msg
+		}
+	}()
+
+	assert.Nil(t, c.request("initialize", map[string]interface{}{}, nil))
+	c.notify("textDocument/didOpen", &lspDidOpenParams{TextDocument: lspTextDocumentItem{URI: "file:///a.txt", Text: "Fine."}})
+	<-w.failed
+
+	// The error is returned once the server reads the next message.
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case err := <-done:
+			assert.ErrorContains(t, err, "broken pipe")
+			return
+		case <-time.After(10 * time.Millisecond):
+			c.conn.notify("initialized", map[string]interface{}{})
+		case <-timeout:
+			t.Fatal("server didn't stop")
+		}
+	}
+}
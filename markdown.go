@@ -0,0 +1,123 @@
+package acrolinx
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// WriteMarkdown writes a summary of docs to w, suitable for comments on
+// pull requests. It lists the score of every document in a table,
+// followed by a collapsible list of issues per document.
+func WriteMarkdown(w io.Writer, docs []*CheckedDocument) error {
+	docs = sortedDocuments(docs)
+
+	var b strings.Builder
+	b.WriteString("## Acrolinx\n\n")
+	b.WriteString("| Document | Score | Status | Issues |\n")
+	b.WriteString("| --- | ---: | --- | ---: |\n")
+
+	for _, doc := range docs {
+		score, status := "", ""
+		if doc.Result != nil && doc.Result.Quality != nil {
+			score = fmt.Sprint(doc.Result.Quality.Score)
+			status = markdownStatus(doc.Result.Quality.Status)
+		}
+
+		fmt.Fprintf(&b, "| %s | %s | %s | %d |\n",
+			markdownCode(doc.slashPath()), score, status, len(doc.locatedIssues()))
+	}
+
+	for _, doc := range docs {
+		issues := doc.locatedIssues()
+		if len(issues) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&b, "\n<details>\n<summary>%s (%s)</summary>\n\n",
+			html.EscapeString(doc.slashPath()), textIssueCount(len(issues)))
+		b.WriteString("| Location | Goal | Issue | Suggestions |\n")
+		b.WriteString("| --- | --- | --- | --- |\n")
+
+		for _, issue := range issues {
+			location := ""
+			if issue.Located {
+				location = fmt.Sprintf("%d:%d", issue.Range.Start.Line, issue.Range.Start.Column)
+			}
+
+			goal := issue.GoalID
+			if g := doc.goal(issue.Issue); g != nil {
+				goal = g.DisplayName
+			}
+
+			suggestions := make([]string, len(issue.Suggestions))
+			for i, s := range issue.Suggestions {
+				suggestions[i] = markdownCode(s.Surface)
+			}
+
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n",
+				location,
+				markdownEscape(goal),
+				markdownEscape(issueTitle(issue.Issue)),
+				strings.Join(suggestions, ", "),
+			)
+		}
+
+		b.WriteString("\n</details>\n")
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("Error writing Markdown report: %w", err)
+	}
+
+	return nil
+}
+
+func markdownStatus(status string) string {
+	switch status {
+	case "red":
+		return "🔴 red"
+	case "yellow":
+		return "🟡 yellow"
+	case "green":
+		return "🟢 green"
+	}
+
+	return status
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	"*", `\*`,
+	"_", `\_`,
+	"[", `\[`,
+	"]", `\]`,
+	"|", `\|`,
+	"<", "&lt;",
+	">", "&gt;",
+	"\r", " ",
+	"\n", " ",
+)
+
+// markdownEscape escapes s for use as text in a table cell.
+func markdownEscape(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// markdownCode formats s as inline code in a table cell.
+func markdownCode(s string) string {
+	s = strings.NewReplacer("|", `\|`, "\r", " ", "\n", " ").Replace(s)
+
+	fence := "`"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		return fence + " " + s + " " + fence
+	}
+
+	return fence + s + fence
+}
@@ -0,0 +1,22 @@
+package acrolinx
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, WriteMarkdown(&buf, reportTestDocuments()))
+	assertGolden(t, "report.md", buf.String())
+}
+
+func TestMarkdownEscape(t *testing.T) {
+	assert.Equal(t, `a \| b \*c\* &lt;d&gt; e`, markdownEscape("a | b *c* <d>\ne"))
+	assert.Equal(t, "`use`", markdownCode("use"))
+	assert.Equal(t, "``a`b``", markdownCode("a`b"))
+	assert.Equal(t, "`` `a ``", markdownCode("`a"))
+	assert.Equal(t, "`a \\| b`", markdownCode("a | b"))
+}
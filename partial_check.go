@@ -0,0 +1,379 @@
+package acrolinx
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FileDiff lists the lines of a file changed by a diff.
+type FileDiff struct {
+	// OldPath and NewPath are the paths of the file before and after
+	// the change, without the a/ and b/ prefixes. They are empty for
+	// added and deleted files.
+	OldPath string
+	NewPath string
+	// Added lists the added lines of the new file, starting at one.
+	Added []int
+	// Deleted lists the lines of the new file following deleted lines.
+	Deleted []int
+}
+
+// Alignment decides how far partial check ranges extend around changed
+// lines.
+type Alignment int
+
+const (
+	// AlignSentences extends ranges to the sentences of changed lines.
+	AlignSentences Alignment = iota
+	// AlignParagraphs extends ranges to the paragraphs of changed lines.
+	AlignParagraphs
+	// AlignLines limits ranges to the changed lines.
+	AlignLines
+)
+
+// ParseUnifiedDiff reads the changed lines of every file in a unified
+// diff, as written by git diff or diff -u.
+func ParseUnifiedDiff(diff string) ([]*FileDiff, error) {
+	var files []*FileDiff
+	var file *FileDiff
+	oldLeft, newLeft, line := 0, 0, 0
+
+	for n, text := range strings.Split(strings.TrimSuffix(diff, "\n"), "\n") {
+		if oldLeft > 0 || newLeft > 0 {
+			switch {
+			case strings.HasPrefix(text, "+"):
+				file.Added = append(file.Added, line)
+				line++
+				newLeft--
+			case strings.HasPrefix(text, "-"):
+				if len(file.Deleted) == 0 || file.Deleted[len(file.Deleted)-1] != line {
+					file.Deleted = append(file.Deleted, line)
+				}
+				oldLeft--
+			case strings.HasPrefix(text, " ") || text == "":
+				line++
+				oldLeft--
+				newLeft--
+			case strings.HasPrefix(text, `\`):
+			default:
+				return nil, fmt.Errorf("Error parsing diff: line %d: unexpected %q in hunk", n+1, text)
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(text, "--- "):
+			file = &FileDiff{OldPath: diffPath(text[4:], "a/")}
+			files = append(files, file)
+		case strings.HasPrefix(text, "+++ "):
+			if file == nil {
+				return nil, fmt.Errorf("Error parsing diff: line %d: +++ without ---", n+1)
+			}
+			file.NewPath = diffPath(text[4:], "b/")
+		case strings.HasPrefix(text, "@@ "):
+			if file == nil {
+				return nil, fmt.Errorf("Error parsing diff: line %d: hunk without file header", n+1)
+			}
+
+			var err error
+			oldLeft, line, newLeft, err = parseHunkHeader(text)
+			if err != nil {
+				return nil, fmt.Errorf("Error parsing diff: line %d: %w", n+1, err)
+			}
+
+			if newLeft == 0 {
+				// Empty ranges refer to the line before them.
+				line++
+			}
+		}
+	}
+
+	if oldLeft > 0 || newLeft > 0 {
+		return nil, fmt.Errorf("Error parsing diff: incomplete hunk")
+	}
+
+	return files, nil
+}
+
+// NewFileDiff compares two versions of a file line by line.
+func NewFileDiff(original string, modified string) *FileDiff {
+	d := &FileDiff{}
+	for _, op := range diffLines(splitLines(original), splitLines(modified)) {
+		switch op.kind {
+		case '+':
+			d.Added = append(d.Added, op.b+1)
+		case '-':
+			if len(d.Deleted) == 0 || d.Deleted[len(d.Deleted)-1] != op.b+1 {
+				d.Deleted = append(d.Deleted, op.b+1)
+			}
+		}
+	}
+
+	return d
+}
+
+// diffPath returns the path of a file header, without the prefix git
+// adds and the timestamp diff -u adds.
+func diffPath(s string, prefix string) string {
+	s, _, _ = strings.Cut(s, "\t")
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+
+	if s == "/dev/null" {
+		return ""
+	}
+
+	return strings.TrimPrefix(s, prefix)
+}
+
+// parseHunkHeader parses "@@ -l,s +l,s @@" into the length of the old
+// range and the start line and length of the new range.
+func parseHunkHeader(s string) (int, int, int, error) {
+	fields := strings.Fields(s)
+	if len(fields) < 4 || fields[3] != "@@" {
+		return 0, 0, 0, fmt.Errorf("invalid hunk header %q", s)
+	}
+
+	_, oldLen, err := parseHunkRange(fields[1], "-")
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid hunk header %q", s)
+	}
+
+	newStart, newLen, err := parseHunkRange(fields[2], "+")
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid hunk header %q", s)
+	}
+
+	return oldLen, newStart, newLen, nil
+}
+
+func parseHunkRange(s string, prefix string) (int, int, error) {
+	s, ok := strings.CutPrefix(s, prefix)
+	if !ok {
+		return 0, 0, fmt.Errorf("missing %s", prefix)
+	}
+
+	start, length, found := strings.Cut(s, ",")
+	n, err := strconv.Atoi(start)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if !found {
+		return n, 1, nil
+	}
+
+	l, err := strconv.Atoi(length)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return n, l, nil
+}
+
+// GitDiff runs git diff in dir to find the lines changed between the
+// revisions from and to, or between from and the working tree if to is
+// empty. Paths limit the diff to some files. The paths of the returned
+// diffs are relative to dir.
+func GitDiff(ctx context.Context, dir string, from string, to string, paths ...string) ([]*FileDiff, error) {
+	// The prefixes are set explicitly, as diff.noprefix and
+	// diff.mnemonicPrefix change them.
+	args := []string{"diff", "--no-color", "--no-ext-diff", "--relative", "--src-prefix=a/", "--dst-prefix=b/", "-U0", from}
+	if to != "" {
+		args = append(args, to)
+	}
+	args = append(args, "--")
+	args = append(args, paths...)
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("Error running git diff: %w: %s", err, msg)
+		}
+		return nil, fmt.Errorf("Error running git diff: %w", err)
+	}
+
+	return ParseUnifiedDiff(string(out))
+}
+
+// FindFileDiff returns the diff of the file at path in its new version,
+// or nil if the file is unchanged.
+func FindFileDiff(diffs []*FileDiff, path string) *FileDiff {
+	for _, d := range diffs {
+		if d.NewPath != "" && d.NewPath == path {
+			return d
+		}
+	}
+
+	return nil
+}
+
+// PartialCheckRanges converts the changed lines of content, the new
+// version of the file, into partial check ranges. Ranges are extended
+// according to align and merged where only whitespace within a
+// paragraph separates them. Deleted lines mark the line following them
+// as changed, or the last line at the end of the file.
+func (d *FileDiff) PartialCheckRanges(content string, align Alignment) []*PartialCheckRange {
+	starts := lineByteStarts(content)
+	lineCount := len(starts) - 1
+
+	lines := append([]int(nil), d.Added...)
+	for _, l := range d.Deleted {
+		lines = append(lines, min(l, lineCount))
+	}
+	sort.Ints(lines)
+
+	// Merge the byte ranges of the lines where only whitespace within
+	// a paragraph is between them.
+	var merged [][2]int
+	for _, l := range lines {
+		if l < 1 || l > lineCount {
+			continue
+		}
+
+		begin, end := alignRange(content, starts[l-1], starts[l], align)
+		if n := len(merged); n > 0 && isLineGap(content[min(merged[n-1][1], begin):begin]) {
+			merged[n-1][1] = max(merged[n-1][1], end)
+			continue
+		}
+		merged = append(merged, [2]int{begin, end})
+	}
+
+	x := NewPositionIndex(content)
+	ranges := make([]*PartialCheckRange, len(merged))
+	for i, r := range merged {
+		ranges[i] = &PartialCheckRange{Begin: x.FromByte(r[0]).UTF16, End: x.FromByte(r[1]).UTF16}
+	}
+
+	return ranges
+}
+
+// isLineGap reports whether gap is whitespace with at most one line
+// break.
+func isLineGap(gap string) bool {
+	return strings.TrimSpace(gap) == "" && strings.Count(gap, "\n") < 2
+}
+
+// lineByteStarts returns the byte offsets of the beginnings of the
+// lines of content, followed by its length.
+func lineByteStarts(content string) []int {
+	starts := []int{0}
+	for i := 0; i < len(content); i++ {
+		if content[i] == '\n' && i+1 < len(content) {
+			starts = append(starts, i+1)
+		}
+	}
+
+	if content == "" {
+		return starts
+	}
+
+	return append(starts, len(content))
+}
+
+// alignRange extends the byte range of a line to the boundaries of
+// align.
+func alignRange(content string, begin int, end int, align Alignment) (int, int) {
+	if align == AlignLines {
+		return begin, end
+	}
+
+	// Ignore the line break, so that ranges don't extend into the
+	// next sentence.
+	last := end
+	for last > begin && isASCIISpace(content[last-1]) {
+		last--
+	}
+	if last == begin {
+		return begin, end
+	}
+
+	paraBegin := strings.LastIndex(content[:begin], "\n\n") + 1
+	if paraBegin > 0 {
+		paraBegin++
+	}
+	paraEnd := len(content)
+	if i := strings.Index(content[last:], "\n\n"); i >= 0 {
+		paraEnd = last + i + 1
+	}
+
+	if align == AlignParagraphs {
+		return paraBegin, paraEnd
+	}
+
+	if i := lastSentenceEnd(content[paraBegin:begin]); i > 0 {
+		begin = paraBegin + i
+	} else {
+		begin = paraBegin
+	}
+
+	if i := firstSentenceEnd(content[last-1 : paraEnd]); i >= 0 {
+		end = last - 1 + i
+	} else {
+		end = paraEnd
+	}
+
+	return begin, end
+}
+
+// firstSentenceEnd returns the position after the first
+// sentence-ending punctuation in s that ends s or is followed by
+// whitespace, or -1.
+func firstSentenceEnd(s string) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '.', '!', '?':
+			if i+1 == len(s) || isASCIISpace(s[i+1]) {
+				return i + 1
+			}
+		}
+	}
+
+	return -1
+}
+
+func isASCIISpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
+
+// FilterIssuesInRanges returns the issues with a match overlapping one
+// of ranges. Issues without matches, which concern the whole document,
+// are removed.
+func FilterIssuesInRanges(issues []*Issue, ranges []*PartialCheckRange) []*Issue {
+	var filtered []*Issue
+	for _, issue := range issues {
+		if issueInRanges(issue, ranges) {
+			filtered = append(filtered, issue)
+		}
+	}
+
+	return filtered
+}
+
+func issueInRanges(issue *Issue, ranges []*PartialCheckRange) bool {
+	if issue.PositionalInformation == nil {
+		return false
+	}
+
+	for _, m := range issue.PositionalInformation.Matches {
+		for _, r := range ranges {
+			if m.OriginalBegin < r.End && m.OriginalEnd > r.Begin ||
+				m.OriginalBegin == m.OriginalEnd && m.OriginalBegin >= r.Begin && m.OriginalBegin < r.End {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,178 @@
+package acrolinx
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const partialCheckContent = `# Title
+
+First sentence. Second sentence
+spans two lines. Third sentence.
+
+Another paragraph. Its second sentence.
+Last line.
+`
+
+func TestParseUnifiedDiff(t *testing.T) {
+	diff := `diff --git a/docs/guide.md b/docs/guide.md
+index 1234567..89abcde 100644
+--- a/docs/guide.md
++++ b/docs/guide.md
+@@ -1,4 +1,4 @@
+ # Title
+
+-First sentence. Second sentence
++First sentence. Second changed sentence
+ spans two lines. Third sentence.
+@@ -7,2 +7,0 @@ Another paragraph.
+-Removed line.
+-Another removed line.
+diff --git a/new.md b/new.md
+new file mode 100644
+--- /dev/null
++++ b/new.md
+@@ -0,0 +1,2 @@
++New
++file
+\ No newline at end of file
+--- "a/with space.txt"	2024-01-01 00:00:00
++++ "b/with space.txt"	2024-01-02 00:00:00
+@@ -1 +1 @@
+-old
++new
+`
+
+	files, err := ParseUnifiedDiff(diff)
+	assert.NoError(t, err)
+	assert.Equal(t, []*FileDiff{
+		{OldPath: "docs/guide.md", NewPath: "docs/guide.md", Added: []int{3}, Deleted: []int{3, 8}},
+		{NewPath: "new.md", Added: []int{1, 2}},
+		{OldPath: "with space.txt", NewPath: "with space.txt", Added: []int{1}, Deleted: []int{1}},
+	}, files)
+
+	assert.Equal(t, files[1], FindFileDiff(files, "new.md"))
+	assert.Nil(t, FindFileDiff(files, "other.md"))
+}
+
+func TestParseUnifiedDiffErrors(t *testing.T) {
+	for _, diff := range []string{
+		"@@ -1 +1 @@\n",
+		"--- a/x\n+++ b/x\n@@ -1 +x @@\n",
+		"--- a/x\n+++ b/x\n@@ -1,2 +1,2 @@\n a\n",
+		"--- a/x\n+++ b/x\n@@ -1,2 +1,2 @@\n a\n?\n",
+	} {
+		_, err := ParseUnifiedDiff(diff)
+		assert.Error(t, err, diff)
+	}
+}
+
+func TestPartialCheckRanges(t *testing.T) {
+	text := func(ranges []*PartialCheckRange) []string {
+		var parts []string
+		for _, r := range ranges {
+			parts = append(parts, partialCheckContent[r.Begin:r.End])
+		}
+		return parts
+	}
+
+	d := &FileDiff{Added: []int{4}}
+	assert.Equal(t, []string{"spans two lines. Third sentence.\n"}, text(d.PartialCheckRanges(partialCheckContent, AlignLines)))
+	assert.Equal(t, []string{"Second sentence\nspans two lines. Third sentence."}, text(d.PartialCheckRanges(partialCheckContent, AlignSentences)))
+	assert.Equal(t, []string{"First sentence. Second sentence\nspans two lines. Third sentence.\n"}, text(d.PartialCheckRanges(partialCheckContent, AlignParagraphs)))
+
+	d = &FileDiff{Added: []int{3, 6}, Deleted: []int{4, 9}}
+	assert.Equal(t, []string{
+		"First sentence. Second sentence\nspans two lines. Third sentence.",
+		"Another paragraph. Its second sentence.\nLast line.",
+	}, text(d.PartialCheckRanges(partialCheckContent, AlignSentences)))
+
+	d = &FileDiff{Added: []int{2}}
+	assert.Equal(t, []string{"\n"}, text(d.PartialCheckRanges(partialCheckContent, AlignSentences)))
+
+	d = &FileDiff{Added: []int{1}}
+	assert.Empty(t, d.PartialCheckRanges("", AlignSentences))
+}
+
+func TestPartialCheckRangesUTF16(t *testing.T) {
+	content := "Ünïcödé 😀 here. Changed line.\n"
+	d := &FileDiff{Added: []int{1}}
+
+	assert.Equal(t, []*PartialCheckRange{{Begin: 0, End: 31}}, d.PartialCheckRanges(content, AlignLines))
+}
+
+func TestFilterIssuesInRanges(t *testing.T) {
+	issue := func(begin, end int) *Issue {
+		return &Issue{PositionalInformation: &PositionalInformation{
+			Matches: []*Match{{OriginalBegin: begin, OriginalEnd: end}},
+		}}
+	}
+
+	issues := []*Issue{issue(0, 5), issue(8, 12), issue(20, 20), issue(30, 40), {GoalID: "document"}}
+	ranges := []*PartialCheckRange{{Begin: 10, End: 25}}
+
+	assert.Equal(t, []*Issue{issues[1], issues[2]}, FilterIssuesInRanges(issues, ranges))
+	assert.Empty(t, FilterIssuesInRanges(issues, nil))
+}
+
+func TestGitDiff(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(out))
+	}
+
+	path := filepath.Join(dir, "docs", "guide.md")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	assert.NoError(t, os.WriteFile(path, []byte("One.\nTwo.\nThree.\n"), 0o644))
+	git("init", "-q")
+	git("add", ".")
+	git("commit", "-q", "-m", "Initial")
+
+	assert.NoError(t, os.WriteFile(path, []byte("One.\nTwo, changed.\nThree.\nFour.\n"), 0o644))
+
+	files, err := GitDiff(context.Background(), dir, "HEAD", "")
+	assert.NoError(t, err)
+	assert.Equal(t, []*FileDiff{
+		{OldPath: "docs/guide.md", NewPath: "docs/guide.md", Added: []int{2, 4}, Deleted: []int{2}},
+	}, files)
+
+	git("commit", "-q", "-a", "-m", "Change")
+
+	files, err = GitDiff(context.Background(), filepath.Join(dir, "docs"), "HEAD~1", "HEAD")
+	assert.NoError(t, err)
+	assert.Equal(t, "guide.md", files[0].NewPath)
+
+	// Configured prefixes don't change the paths.
+	notes := filepath.Join(dir, "b", "notes.md")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(notes), 0o755))
+	assert.NoError(t, os.WriteFile(notes, []byte("Notes.\n"), 0o644))
+	git("add", ".")
+	git("commit", "-q", "-m", "Notes")
+	assert.NoError(t, os.WriteFile(notes, []byte("Notes, changed.\n"), 0o644))
+
+	for _, config := range []string{"diff.noprefix", "diff.mnemonicPrefix"} {
+		git("config", config, "true")
+		files, err = GitDiff(context.Background(), dir, "HEAD", "")
+		assert.NoError(t, err)
+		if assert.Len(t, files, 1, config) {
+			assert.Equal(t, "b/notes.md", files[0].OldPath, config)
+			assert.Equal(t, "b/notes.md", files[0].NewPath, config)
+		}
+		git("config", "--unset", config)
+	}
+
+	_, err = GitDiff(context.Background(), dir, "no-such-revision", "")
+	assert.ErrorContains(t, err, "Error running git diff")
+}
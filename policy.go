@@ -0,0 +1,170 @@
+package acrolinx
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	PolicyRuleMinScore         = "min-score"
+	PolicyRuleMinGoalScore     = "min-goal-score"
+	PolicyRuleMaxGoalIssues    = "max-goal-issues"
+	PolicyRuleMaxScoringIssues = "max-scoring-issues"
+	PolicyRuleStatus           = "status"
+)
+
+// QualityPolicy defines the thresholds a check result must meet.
+// Thresholds that are not set are not checked.
+type QualityPolicy struct {
+	// MinScore is the minimum overall quality score.
+	MinScore int `json:"minScore,omitempty"`
+	// MinGoalScores maps goal IDs to their minimum score.
+	MinGoalScores map[string]int `json:"minGoalScores,omitempty"`
+	// MaxGoalIssues maps goal IDs to the maximum number of issues.
+	MaxGoalIssues map[string]int `json:"maxGoalIssues,omitempty"`
+	// MaxScoringIssues maps scoring levels, like "required", to the
+	// maximum number of issues. Issues without a scoring level use the
+	// one of their goal.
+	MaxScoringIssues map[string]int `json:"maxScoringIssues,omitempty"`
+	// AllowedStatuses lists the accepted quality statuses, like "green"
+	// or "yellow". If empty, all statuses are accepted.
+	AllowedStatuses []string `json:"allowedStatuses,omitempty"`
+}
+
+// Violation describes a threshold of a policy that a result doesn't
+// meet.
+type Violation struct {
+	Rule string
+	// Key is the goal ID or scoring level the threshold applies to.
+	Key     string
+	Message string
+}
+
+type Verdict struct {
+	Passed     bool
+	Violations []*Violation
+}
+
+type DocumentVerdict struct {
+	Document *CheckedDocument
+	*Verdict
+}
+
+type BatchVerdict struct {
+	Passed    bool
+	Documents []*DocumentVerdict
+}
+
+// Failed returns the verdicts of the documents that failed.
+func (b *BatchVerdict) Failed() []*DocumentVerdict {
+	var failed []*DocumentVerdict
+	for _, d := range b.Documents {
+		if !d.Passed {
+			failed = append(failed, d)
+		}
+	}
+
+	return failed
+}
+
+// Evaluate checks result against the policy.
+func (p *QualityPolicy) Evaluate(result *CheckResult) *Verdict {
+	return p.evaluate(&CheckedDocument{Result: result})
+}
+
+// EvaluateBatch checks the result of every document against the
+// policy. The batch passes if all documents pass.
+func (p *QualityPolicy) EvaluateBatch(docs []*CheckedDocument) *BatchVerdict {
+	batch := &BatchVerdict{Passed: true}
+	for _, doc := range docs {
+		v := p.evaluate(doc)
+		batch.Documents = append(batch.Documents, &DocumentVerdict{doc, v})
+		batch.Passed = batch.Passed && v.Passed
+	}
+
+	return batch
+}
+
+func (p *QualityPolicy) evaluate(doc *CheckedDocument) *Verdict {
+	v := &Verdict{}
+	add := func(rule, key, format string, args ...interface{}) {
+		v.Violations = append(v.Violations, &Violation{rule, key, fmt.Sprintf(format, args...)})
+	}
+
+	result := doc.Result
+	if result == nil {
+		result = &CheckResult{}
+	}
+	p.evaluateQuality(result.Quality, add)
+
+	if len(p.MaxGoalIssues) > 0 || len(p.MaxScoringIssues) > 0 {
+		byGoal := make(map[string]int)
+		byScoring := make(map[string]int)
+		for _, issue := range result.Issues {
+			byGoal[issue.GoalID]++
+			byScoring[doc.scoring(issue)]++
+		}
+
+		for _, goal := range sortedKeys(p.MaxGoalIssues) {
+			if n, limit := byGoal[goal], p.MaxGoalIssues[goal]; n > limit {
+				add(PolicyRuleMaxGoalIssues, goal, "%d issues for goal %s exceed the maximum of %d", n, goal, limit)
+			}
+		}
+
+		for _, scoring := range sortedKeys(p.MaxScoringIssues) {
+			if n, limit := byScoring[scoring], p.MaxScoringIssues[scoring]; n > limit {
+				add(PolicyRuleMaxScoringIssues, scoring, "%d %s issues exceed the maximum of %d", n, scoring, limit)
+			}
+		}
+	}
+
+	v.Passed = len(v.Violations) == 0
+	return v
+}
+
+// evaluateQuality checks the thresholds on the quality of a result.
+// Without quality, every threshold set is violated.
+func (p *QualityPolicy) evaluateQuality(quality *Quality, add func(rule, key, format string, args ...interface{})) {
+	switch {
+	case p.MinScore <= 0:
+	case quality == nil:
+		add(PolicyRuleMinScore, "", "result has no quality score")
+	case quality.Score < p.MinScore:
+		add(PolicyRuleMinScore, "", "score %d is below the minimum of %d", quality.Score, p.MinScore)
+	}
+
+	var scores []*Score
+	if quality != nil {
+		scores = quality.ScoresByGoal
+	}
+	for _, goal := range sortedKeys(p.MinGoalScores) {
+		limit := p.MinGoalScores[goal]
+		i := slices.IndexFunc(scores, func(s *Score) bool { return s.ID == goal })
+		switch {
+		case i < 0:
+			add(PolicyRuleMinGoalScore, goal, "no score for goal %s", goal)
+		case scores[i].Score < limit:
+			add(PolicyRuleMinGoalScore, goal, "score %d for goal %s is below the minimum of %d", scores[i].Score, goal, limit)
+		}
+	}
+
+	switch {
+	case len(p.AllowedStatuses) == 0:
+	case quality == nil:
+		add(PolicyRuleStatus, "", "result has no quality status")
+	case !slices.Contains(p.AllowedStatuses, quality.Status):
+		add(PolicyRuleStatus, "", "status %q is not one of %s", quality.Status, strings.Join(p.AllowedStatuses, ", "))
+	}
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
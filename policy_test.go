@@ -0,0 +1,96 @@
+package acrolinx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func violationMessages(v *Verdict) []string {
+	var messages []string
+	for _, violation := range v.Violations {
+		messages = append(messages, violation.Message)
+	}
+
+	return messages
+}
+
+func TestQualityPolicyEvaluate(t *testing.T) {
+	result := reportTestDocuments()[0].Result
+
+	v := (&QualityPolicy{}).Evaluate(result)
+	assert.True(t, v.Passed)
+	assert.Empty(t, v.Violations)
+
+	v = (&QualityPolicy{
+		MinScore:        70,
+		MinGoalScores:   map[string]int{"CLARITY": 50, "CONSISTENCY": 80},
+		MaxGoalIssues:   map[string]int{"CLARITY": 3},
+		AllowedStatuses: []string{"green", "yellow"},
+	}).Evaluate(result)
+	assert.True(t, v.Passed)
+
+	v = (&QualityPolicy{
+		MinScore:         80,
+		MinGoalScores:    map[string]int{"CONSISTENCY": 95, "CLARITY": 65, "TERMINOLOGY": 50},
+		MaxGoalIssues:    map[string]int{"CLARITY": 2, "CONSISTENCY": 1},
+		MaxScoringIssues: map[string]int{"required": 0, "optional": 1},
+		AllowedStatuses:  []string{"green"},
+	}).Evaluate(result)
+	assert.False(t, v.Passed)
+	assert.Equal(t, []string{
+		"score 74 is below the minimum of 80",
+		"score 60 for goal CLARITY is below the minimum of 65",
+		"score 90 for goal CONSISTENCY is below the minimum of 95",
+		"no score for goal TERMINOLOGY",
+		`status "yellow" is not one of green`,
+		"3 issues for goal CLARITY exceed the maximum of 2",
+		"3 required issues exceed the maximum of 0",
+	}, violationMessages(v))
+
+	assert.Equal(t, &Violation{PolicyRuleMaxScoringIssues, "required", "3 required issues exceed the maximum of 0"}, v.Violations[6])
+}
+
+func TestQualityPolicyWithoutQuality(t *testing.T) {
+	policy := &QualityPolicy{MinScore: 50, MaxGoalIssues: map[string]int{"CLARITY": 0}}
+
+	v := policy.Evaluate(&CheckResult{Issues: []*Issue{{GoalID: "CLARITY"}}})
+	assert.False(t, v.Passed)
+	assert.Equal(t, []string{
+		"result has no quality score",
+		"1 issues for goal CLARITY exceed the maximum of 0",
+	}, violationMessages(v))
+
+	v = (&QualityPolicy{MaxGoalIssues: map[string]int{"CLARITY": 0}}).Evaluate(nil)
+	assert.True(t, v.Passed)
+
+	// Every threshold on the quality is violated with its own rule.
+	policy = &QualityPolicy{
+		MinGoalScores:   map[string]int{"CLARITY": 50, "TONE": 60},
+		AllowedStatuses: []string{"green"},
+	}
+	v = policy.Evaluate(&CheckResult{})
+	assert.Equal(t, []*Violation{
+		{PolicyRuleMinGoalScore, "CLARITY", "no score for goal CLARITY"},
+		{PolicyRuleMinGoalScore, "TONE", "no score for goal TONE"},
+		{PolicyRuleStatus, "", "result has no quality status"},
+	}, v.Violations)
+}
+
+func TestQualityPolicyEvaluateBatch(t *testing.T) {
+	docs := reportTestDocuments()
+	policy := &QualityPolicy{MinScore: 80}
+
+	batch := policy.EvaluateBatch(docs)
+	assert.False(t, batch.Passed)
+	assert.Len(t, batch.Documents, 2)
+	assert.False(t, batch.Documents[0].Passed)
+	assert.True(t, batch.Documents[1].Passed)
+
+	failed := batch.Failed()
+	assert.Len(t, failed, 1)
+	assert.Equal(t, "docs/guide.md", failed[0].Document.Path)
+
+	assert.True(t, policy.EvaluateBatch(docs[1:]).Passed)
+	assert.True(t, policy.EvaluateBatch(nil).Passed)
+}
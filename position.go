@@ -0,0 +1,176 @@
+package acrolinx
+
+import (
+	"sort"
+	"unicode/utf8"
+)
+
+// Position is a location in a document, expressed in several units.
+// Offsets are zero-based, lines and columns start at one. Match offsets
+// reported by the platform are in UTF-16 code units.
+type Position struct {
+	Byte  int
+	Rune  int
+	UTF16 int
+
+	Line int
+
+	// Column counts runes, ByteColumn bytes and UTF16Column UTF-16
+	// code units.
+	Column      int
+	ByteColumn  int
+	UTF16Column int
+}
+
+type Range struct {
+	Start Position
+	End   Position
+}
+
+type lineStart struct {
+	byte  int
+	rune  int
+	utf16 int
+}
+
+// PositionIndex converts offsets in a document between bytes, runes,
+// UTF-16 code units and lines and columns.
+type PositionIndex struct {
+	content string
+	lines   []lineStart
+	total   lineStart
+}
+
+func NewPositionIndex(content string) *PositionIndex {
+	x := &PositionIndex{
+		content: content,
+		lines:   []lineStart{{}},
+	}
+
+	runes, units := 0, 0
+	for i := 0; i < len(content); {
+		r, size := utf8.DecodeRuneInString(content[i:])
+		i += size
+		runes++
+		units += utf16Units(r)
+
+		if r == '\n' || (r == '\r' && (i == len(content) || content[i] != '\n')) {
+			x.lines = append(x.lines, lineStart{i, runes, units})
+		}
+	}
+	x.total = lineStart{len(content), runes, units}
+
+	return x
+}
+
+func utf16Units(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+
+	return 1
+}
+
+// Len returns the length of the document in UTF-16 code units.
+func (x *PositionIndex) Len() int {
+	return x.total.utf16
+}
+
+// FromUTF16 returns the position of a UTF-16 offset. Offsets pointing
+// into a surrogate pair resolve to the start of its rune.
+func (x *PositionIndex) FromUTF16(offset int) Position {
+	offset = clamp(offset, x.total.utf16)
+	line := sort.Search(len(x.lines), func(i int) bool { return x.lines[i].utf16 > offset }) - 1
+
+	return x.walk(line, func(p lineStart, r rune) bool {
+		return p.utf16+utf16Units(r) > offset
+	})
+}
+
+// FromByte returns the position of a byte offset. Offsets pointing
+// into a multi-byte rune resolve to the start of the rune.
+func (x *PositionIndex) FromByte(offset int) Position {
+	offset = clamp(offset, x.total.byte)
+	line := sort.Search(len(x.lines), func(i int) bool { return x.lines[i].byte > offset }) - 1
+
+	return x.walk(line, func(p lineStart, r rune) bool {
+		return p.byte+utf8.RuneLen(r) > offset
+	})
+}
+
+func (x *PositionIndex) FromRune(offset int) Position {
+	offset = clamp(offset, x.total.rune)
+	line := sort.Search(len(x.lines), func(i int) bool { return x.lines[i].rune > offset }) - 1
+
+	return x.walk(line, func(p lineStart, r rune) bool {
+		return p.rune >= offset
+	})
+}
+
+// walk advances rune by rune from the start of line until stop returns
+// true for the rune at the current position.
+func (x *PositionIndex) walk(line int, stop func(lineStart, rune) bool) Position {
+	start := x.lines[line]
+	p := start
+
+	for p.byte < len(x.content) {
+		r, size := utf8.DecodeRuneInString(x.content[p.byte:])
+		if stop(p, r) {
+			break
+		}
+
+		p.byte += size
+		p.rune++
+		p.utf16 += utf16Units(r)
+	}
+
+	return Position{
+		Byte:        p.byte,
+		Rune:        p.rune,
+		UTF16:       p.utf16,
+		Line:        line + 1,
+		Column:      p.rune - start.rune + 1,
+		ByteColumn:  p.byte - start.byte + 1,
+		UTF16Column: p.utf16 - start.utf16 + 1,
+	}
+}
+
+func clamp(offset int, length int) int {
+	return max(0, min(offset, length))
+}
+
+// MatchRange returns the range of the original part of m.
+func (x *PositionIndex) MatchRange(m *Match) Range {
+	return Range{x.FromUTF16(m.OriginalBegin), x.FromUTF16(m.OriginalEnd)}
+}
+
+// IssueRange returns the range spanning all matches of issue. It
+// returns false if the issue has no matches.
+func (x *PositionIndex) IssueRange(issue *Issue) (Range, bool) {
+	if issue.PositionalInformation == nil || len(issue.PositionalInformation.Matches) == 0 {
+		return Range{}, false
+	}
+
+	matches := issue.PositionalInformation.Matches
+	begin, end := matches[0].OriginalBegin, matches[0].OriginalEnd
+	for _, m := range matches[1:] {
+		begin = min(begin, m.OriginalBegin)
+		end = max(end, m.OriginalEnd)
+	}
+
+	return Range{x.FromUTF16(begin), x.FromUTF16(end)}, true
+}
+
+// Annotate sets the Range of every issue and sub-issue in result.
+func (x *PositionIndex) Annotate(result *CheckResult) {
+	x.annotateIssues(result.Issues)
+}
+
+func (x *PositionIndex) annotateIssues(issues []*Issue) {
+	for _, issue := range issues {
+		if r, ok := x.IssueRange(issue); ok {
+			issue.Range = &r
+		}
+		x.annotateIssues(issue.SubIssues)
+	}
+}
@@ -0,0 +1,101 @@
+package acrolinx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The emoji takes 4 bytes, 1 rune and 2 UTF-16 code units, each CJK
+// character 3 bytes, 1 rune and 1 code unit.
+const positionTestContent = "Hi 😀 there\n日本語 text\r\nlast line"
+
+func TestPositionIndexFromUTF16(t *testing.T) {
+	x := NewPositionIndex(positionTestContent)
+
+	assert.Equal(t, Position{Byte: 0, Rune: 0, UTF16: 0, Line: 1, Column: 1, ByteColumn: 1, UTF16Column: 1}, x.FromUTF16(0))
+	assert.Equal(t, Position{Byte: 3, Rune: 3, UTF16: 3, Line: 1, Column: 4, ByteColumn: 4, UTF16Column: 4}, x.FromUTF16(3))
+	assert.Equal(t, Position{Byte: 7, Rune: 4, UTF16: 5, Line: 1, Column: 5, ByteColumn: 8, UTF16Column: 6}, x.FromUTF16(5))
+	assert.Equal(t, Position{Byte: 8, Rune: 5, UTF16: 6, Line: 1, Column: 6, ByteColumn: 9, UTF16Column: 7}, x.FromUTF16(6))
+	assert.Equal(t, Position{Byte: 14, Rune: 11, UTF16: 12, Line: 2, Column: 1, ByteColumn: 1, UTF16Column: 1}, x.FromUTF16(12))
+	assert.Equal(t, Position{Byte: 20, Rune: 13, UTF16: 14, Line: 2, Column: 3, ByteColumn: 7, UTF16Column: 3}, x.FromUTF16(14))
+	assert.Equal(t, Position{Byte: 30, Rune: 21, UTF16: 22, Line: 3, Column: 1, ByteColumn: 1, UTF16Column: 1}, x.FromUTF16(22))
+}
+
+func TestPositionIndexSurrogatePair(t *testing.T) {
+	x := NewPositionIndex(positionTestContent)
+
+	// Offset 4 points between the two halves of the emoji.
+	assert.Equal(t, x.FromUTF16(3), x.FromUTF16(4))
+}
+
+func TestPositionIndexFromByteAndRune(t *testing.T) {
+	x := NewPositionIndex(positionTestContent)
+
+	assert.Equal(t, x.FromUTF16(14), x.FromByte(20))
+	assert.Equal(t, x.FromUTF16(14), x.FromRune(13))
+	assert.Equal(t, x.FromByte(3), x.FromByte(5))
+	assert.Equal(t, x.FromUTF16(5), x.FromRune(4))
+}
+
+func TestPositionIndexClamps(t *testing.T) {
+	x := NewPositionIndex(positionTestContent)
+
+	assert.Equal(t, x.FromUTF16(0), x.FromUTF16(-5))
+	end := x.FromUTF16(1000)
+	assert.Equal(t, len(positionTestContent), end.Byte)
+	assert.Equal(t, x.Len(), end.UTF16)
+	assert.Equal(t, 3, end.Line)
+	assert.Equal(t, 10, end.Column)
+}
+
+func TestPositionIndexLineBreaks(t *testing.T) {
+	x := NewPositionIndex("a\rb\r\nc\nd")
+
+	assert.Equal(t, 2, x.FromByte(2).Line)
+	assert.Equal(t, 2, x.FromByte(4).Line)
+	assert.Equal(t, 3, x.FromByte(5).Line)
+	assert.Equal(t, 4, x.FromByte(7).Line)
+}
+
+func TestPositionIndexAnnotate(t *testing.T) {
+	x := NewPositionIndex(positionTestContent)
+
+	result := &CheckResult{
+		Issues: []*Issue{
+			{
+				PositionalInformation: &PositionalInformation{
+					Matches: []*Match{
+						{OriginalPart: "語", OriginalBegin: 14, OriginalEnd: 15},
+						{OriginalPart: "日", OriginalBegin: 12, OriginalEnd: 13},
+					},
+				},
+				SubIssues: []*Issue{
+					{PositionalInformation: &PositionalInformation{Matches: []*Match{{OriginalBegin: 3, OriginalEnd: 5}}}},
+				},
+			},
+			{},
+		},
+	}
+	x.Annotate(result)
+
+	r := result.Issues[0].Range
+	assert.Equal(t, 2, r.Start.Line)
+	assert.Equal(t, 1, r.Start.Column)
+	assert.Equal(t, 4, r.End.Column)
+	assert.Equal(t, "日本語", positionTestContent[r.Start.Byte:r.End.Byte])
+
+	sub := result.Issues[0].SubIssues[0].Range
+	assert.Equal(t, "😀", positionTestContent[sub.Start.Byte:sub.End.Byte])
+	assert.Equal(t, 1, sub.End.Column-sub.Start.Column)
+	assert.Equal(t, 2, sub.End.UTF16Column-sub.Start.UTF16Column)
+
+	assert.Nil(t, result.Issues[1].Range)
+}
+
+func TestPositionIndexMatchRange(t *testing.T) {
+	x := NewPositionIndex(positionTestContent)
+
+	r := x.MatchRange(&Match{OriginalBegin: 6, OriginalEnd: 11})
+	assert.Equal(t, "there", positionTestContent[r.Start.Byte:r.End.Byte])
+}
@@ -0,0 +1,162 @@
+package acrolinx
+
+import (
+	"html"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+// CheckedDocument is a checked document along with its result. It is
+// the input of all report formats. Content is empty for binary
+// documents, like office documents, whose issues have no location.
+type CheckedDocument struct {
+	Path    string
+	Content string
+	Result  *CheckResult
+}
+
+// locatedIssue is an issue together with its location in the document.
+type locatedIssue struct {
+	*Issue
+	Range Range
+	// Located is false for issues without matches and for documents
+	// without content.
+	Located bool
+}
+
+// locatedIssues returns the issues of doc sorted by their position.
+func (d *CheckedDocument) locatedIssues() []*locatedIssue {
+	if d.Result == nil {
+		return nil
+	}
+
+	x := NewPositionIndex(d.Content)
+	issues := make([]*locatedIssue, len(d.Result.Issues))
+	for i, issue := range d.Result.Issues {
+		r, ok := d.issueRange(x, issue)
+		issues[i] = &locatedIssue{issue, r, ok}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		a, b := issues[i], issues[j]
+		if a.Range.Start.UTF16 != b.Range.Start.UTF16 {
+			return a.Range.Start.UTF16 < b.Range.Start.UTF16
+		}
+		if a.Range.End.UTF16 != b.Range.End.UTF16 {
+			return a.Range.End.UTF16 < b.Range.End.UTF16
+		}
+		return a.InternalName < b.InternalName
+	})
+
+	return issues
+}
+
+// issueRange returns the range of issue in the content of d. Without
+// content, like for results fetched by check ID, issues have no range.
+func (d *CheckedDocument) issueRange(x *PositionIndex, issue *Issue) (Range, bool) {
+	if d.Content == "" {
+		return Range{}, false
+	}
+
+	return x.IssueRange(issue)
+}
+
+// slashPath returns the path of the document with forward slashes.
+func (d *CheckedDocument) slashPath() string {
+	return filepath.ToSlash(d.Path)
+}
+
+// goal returns the goal of issue as listed in the result, or nil.
+func (d *CheckedDocument) goal(issue *Issue) *Goal {
+	if d.Result == nil {
+		return nil
+	}
+
+	for _, g := range d.Result.Goals {
+		if g.ID == issue.GoalID {
+			return g
+		}
+	}
+
+	return nil
+}
+
+// scoring returns the scoring level of an issue, or else of its goal.
+func (d *CheckedDocument) scoring(issue *Issue) string {
+	if issue.Scoring != "" {
+		return issue.Scoring
+	}
+
+	if g := d.goal(issue); g != nil {
+		return g.Scoring
+	}
+
+	return ""
+}
+
+// severity maps the scoring level of an issue to a severity. Required
+// issues are errors, all others warnings.
+func (d *CheckedDocument) severity(issue *Issue) string {
+	switch d.scoring(issue) {
+	case "required":
+		return SeverityError
+	case "":
+		return SeverityInfo
+	}
+
+	return SeverityWarning
+}
+
+// issueTitle returns a one-line description of issue.
+func issueTitle(issue *Issue) string {
+	if title := htmlToText(issue.DisplayNameHTML); title != "" {
+		return strings.Join(strings.Fields(title), " ")
+	}
+
+	if issue.DisplaySurface != "" {
+		return issue.DisplaySurface
+	}
+
+	return issue.InternalName
+}
+
+// issueRuleID identifies the rule an issue was found by.
+func issueRuleID(issue *Issue) string {
+	if issue.InternalName != "" {
+		return issue.InternalName
+	}
+
+	return issue.GoalID
+}
+
+var (
+	htmlBlockEnd = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|h[1-6]|tr|ul|ol|blockquote)>`)
+	htmlListItem = regexp.MustCompile(`(?i)<li[^>]*>`)
+	htmlTag      = regexp.MustCompile(`<[^>]*>`)
+	blankLines   = regexp.MustCompile(`\n{3,}`)
+)
+
+// htmlToText converts the HTML snippets used for issue names and
+// guidance to plain text.
+func htmlToText(s string) string {
+	s = htmlBlockEnd.ReplaceAllString(s, "\n")
+	s = htmlListItem.ReplaceAllString(s, "\n- ")
+	s = htmlTag.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	s = strings.Join(lines, "\n")
+
+	return strings.TrimSpace(blankLines.ReplaceAllString(s, "\n\n"))
+}
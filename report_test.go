@@ -0,0 +1,185 @@
+package acrolinx
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files in testdata")
+
+// assertGolden compares actual with the golden file testdata/name,
+// rewriting it instead if the tests run with -update.
+func assertGolden(t *testing.T, name string, actual string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.WriteFile(path, []byte(actual), 0o644); err != nil {
+			t.Fatalf("Error writing golden file %s: %v", path, err)
+		}
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Error reading golden file %s: %v", path, err)
+	}
+
+	assert.Equal(t, string(expected), actual)
+}
+
+// reportMatch returns a match for the first occurrence of part in
+// content.
+func reportMatch(content string, part string) *Match {
+	return reportMatchAt(content, strings.Index(content, part), part)
+}
+
+// reportMatchAt returns a match for part at byte offset i of content.
+func reportMatchAt(content string, i int, part string) *Match {
+	begin := utf16Length(content[:i])
+	end := begin + utf16Length(part)
+
+	return &Match{part, begin, end, part, begin, end}
+}
+
+const reportTestContent = "# Guide\n\nIn most cases you can utilise the tool.\n日本語 😀 is not supported.\n"
+
+func reportTestDocuments() []*CheckedDocument {
+	content := reportTestContent
+	isNot := strings.Index(content, "is not")
+
+	return []*CheckedDocument{
+		{
+			Path:    "docs/guide.md",
+			Content: content,
+			Result: &CheckResult{
+				ID: "check-1",
+				Document: &ResponseDocument{
+					DisplayInfo: &DisplayInfo{Reference: "docs/guide.md"},
+				},
+				Quality: &Quality{
+					Score:        74,
+					Status:       "yellow",
+					ScoresByGoal: []*Score{{"CLARITY", 60}, {"CONSISTENCY", 90}},
+				},
+				Counts: &Counts{Sentences: 3, Words: 15, Issues: 3, ScoredIssues: 2},
+				Goals: []*Goal{
+					{ID: "CLARITY", DisplayName: "Clarity", Color: "#ec407a", Scoring: "required", Issues: 2},
+					{ID: "CONSISTENCY", DisplayName: "Consistency", Color: "#ffd600", Scoring: "optional", Issues: 1},
+				},
+				Issues: []*Issue{
+					{
+						GoalID:          "CONSISTENCY",
+						InternalName:    "contraction",
+						DisplayNameHTML: "<div>Use the contraction <q>isn’t</q>.</div>",
+						GuidanceHTML:    "<p>Contractions make text friendlier.</p><ul><li>Use <b>isn’t</b></li><li>Use <b>aren’t</b></li></ul>",
+						DisplaySurface:  "is not",
+						PositionalInformation: &PositionalInformation{
+							Hashes: &Hashes{Issue: "aXNzdWUtMw==", Environment: "ZW52LTM=", Index: "aW5kZXgtMw=="},
+							Matches: []*Match{
+								reportMatchAt(content, isNot, "is"),
+								reportMatchAt(content, isNot+2, " "),
+								reportMatchAt(content, isNot+3, "not"),
+							},
+						},
+						Suggestions: []*Suggestion{{Surface: "isn’t", Replacements: []string{"isn’t", "", ""}}},
+					},
+					{
+						GoalID:          "CLARITY",
+						InternalName:    "simpler_word",
+						DisplayNameHTML: "<div>Use a simpler word</div>",
+						GuidanceHTML:    "<div lang=\"en\">Simple words are easier to read &amp; understand.</div>",
+						DisplaySurface:  "utilise",
+						Scoring:         "required",
+						PositionalInformation: &PositionalInformation{
+							Hashes:  &Hashes{Issue: "aXNzdWUtMg==", Environment: "ZW52LTI=", Index: "aW5kZXgtMg=="},
+							Matches: []*Match{reportMatch(content, "utilise")},
+						},
+						Suggestions: []*Suggestion{
+							{Surface: "use", Replacements: []string{"use"}},
+							{Surface: "employ", Replacements: []string{"employ"}},
+						},
+						Links: Links{"help": "https://example.com/help/simpler_word"},
+					},
+					{
+						GoalID:          "CLARITY",
+						InternalName:    "use_comma_after_introductory_phrase",
+						DisplayNameHTML: "<div lang=\"en\">Could you add a comma after the introductory phrase?</div>",
+						GuidanceHTML:    "<div lang=\"en\">If you use a comma after an introductory phrase, your content will be easier to read.</div>",
+						DisplaySurface:  "In most cases",
+						Scoring:         "required",
+						PositionalInformation: &PositionalInformation{
+							Hashes:  &Hashes{Issue: "aXNzdWUtMQ==", Environment: "ZW52LTE=", Index: "aW5kZXgtMQ=="},
+							Matches: []*Match{reportMatch(content, "In most cases")},
+						},
+					},
+					{
+						GoalID:          "CLARITY",
+						InternalName:    "document_too_long",
+						DisplayNameHTML: "<div>This document is long.</div>",
+						ReadOnly:        true,
+					},
+				},
+			},
+		},
+		{
+			Path:    "docs/empty.md",
+			Content: "Nothing to see.\n",
+			Result: &CheckResult{
+				ID:      "check-2",
+				Quality: &Quality{Score: 100, Status: "green"},
+				Counts:  &Counts{Sentences: 1, Words: 3},
+				Goals: []*Goal{
+					{ID: "CLARITY", DisplayName: "Clarity", Color: "#ec407a", Scoring: "required"},
+				},
+			},
+		},
+	}
+}
+
+func TestHTMLToText(t *testing.T) {
+	assert.Equal(t, "Could you add a comma?", htmlToText("<div lang=\"en\">Could you add a comma?</div>"))
+	assert.Equal(t, "Intro\n\n- one\n- two", htmlToText("<p>Intro</p><ul><li>one</li><li>two</li></ul>"))
+	assert.Equal(t, "a < b & c\nnext line", htmlToText("a &lt; b &amp; c<br/>next   line"))
+	assert.Equal(t, "", htmlToText(""))
+}
+
+func TestLocatedIssues(t *testing.T) {
+	doc := reportTestDocuments()[0]
+
+	var names []string
+	for _, issue := range doc.locatedIssues() {
+		names = append(names, issue.InternalName)
+	}
+
+	assert.Equal(t, []string{"document_too_long", "use_comma_after_introductory_phrase", "simpler_word", "contraction"}, names)
+}
+
+func TestLocatedIssuesWithoutContent(t *testing.T) {
+	doc := reportTestDocuments()[0]
+	doc.Content = ""
+
+	for _, issue := range doc.locatedIssues() {
+		assert.False(t, issue.Located, issue.InternalName)
+		assert.Equal(t, Range{}, issue.Range)
+	}
+
+	for _, r := range NewIssueRecords([]*CheckedDocument{doc}) {
+		assert.Nil(t, r.Line, r.InternalName)
+		assert.Nil(t, r.Begin, r.InternalName)
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	doc := reportTestDocuments()[0]
+	issues := doc.Result.Issues
+
+	assert.Equal(t, SeverityWarning, doc.severity(issues[0]))
+	assert.Equal(t, SeverityError, doc.severity(issues[1]))
+	assert.Equal(t, SeverityError, doc.severity(&Issue{GoalID: "CLARITY"}))
+	assert.Equal(t, SeverityInfo, doc.severity(&Issue{GoalID: "UNKNOWN"}))
+}
@@ -0,0 +1,342 @@
+package acrolinx
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// CachedResult is a check result as kept by a ResultStore.
+type CachedResult struct {
+	Result   *CheckResult `json:"result"`
+	StoredAt time.Time    `json:"storedAt"`
+}
+
+// ResultStore is the storage backend of a ResultCache. Get returns a
+// nil entry and no error if nothing is stored under key.
+type ResultStore interface {
+	Get(key string) (*CachedResult, error)
+	Put(key string, entry *CachedResult) error
+	Delete(key string) error
+	Clear() error
+}
+
+type ResultCacheOptions struct {
+	// TTL is the maximum age of a cached result. Zero means results
+	// never expire.
+	TTL time.Duration
+
+	// Capabilities of the platform the results were obtained
+	// from. Their fingerprint is part of every cache key, so results
+	// are invalidated when the platform configuration changes.
+	Capabilities *Capabilities
+}
+
+// ResultCache maps check requests to previously obtained results.
+type ResultCache struct {
+	store       ResultStore
+	ttl         time.Duration
+	fingerprint string
+	now         func() time.Time
+}
+
+func NewResultCache(store ResultStore, opts *ResultCacheOptions) *ResultCache {
+	cache := &ResultCache{
+		store: store,
+		now:   time.Now,
+	}
+
+	if opts != nil {
+		cache.ttl = opts.TTL
+		if opts.Capabilities != nil {
+			cache.fingerprint = opts.Capabilities.Fingerprint()
+		}
+	}
+
+	return cache
+}
+
+// Key derives the cache key of a check request from the content hash,
+// content encoding, language, check options except the batch ID,
+// document reference and custom fields, and the capabilities
+// fingerprint.
+func (c *ResultCache) Key(opts *SubmitCheckOptions) string {
+	contentHash := sha256.Sum256([]byte(opts.Content))
+
+	h := sha256.New()
+	writeKeyPart := func(part string) {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+
+	writeKeyPart(hex.EncodeToString(contentHash[:]))
+	writeKeyPart(opts.ContentEncoding)
+	writeKeyPart(opts.Language)
+
+	o := opts.CheckOptions
+	if o == nil {
+		o = &CheckOptions{}
+	}
+	writeKeyPart(o.GuidanceProfileID)
+	writeKeyPart(o.ContentFormat)
+	writeKeyPart(o.CheckType)
+
+	// The order of report types doesn't change the result.
+	reportTypes := append([]string(nil), o.ReportTypes...)
+	sort.Strings(reportTypes)
+	writeKeyPart(strconv.Itoa(len(reportTypes)))
+	for _, t := range reportTypes {
+		writeKeyPart(t)
+	}
+
+	writeKeyPart(strconv.Itoa(len(o.PartialCheckRanges)))
+	for _, r := range o.PartialCheckRanges {
+		writeKeyPart(strconv.Itoa(r.Begin) + "-" + strconv.Itoa(r.End))
+	}
+
+	doc := opts.Document
+	if doc == nil {
+		doc = &Document{}
+	}
+	writeKeyPart(doc.Reference)
+	writeKeyPart(strconv.Itoa(len(doc.CustomFields)))
+	for _, f := range doc.CustomFields {
+		writeKeyPart(f.Key)
+		writeKeyPart(f.Value)
+	}
+
+	writeKeyPart(c.fingerprint)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Get returns the cached result for opts, or nil if there is no
+// result or it has expired.
+func (c *ResultCache) Get(opts *SubmitCheckOptions) (*CheckResult, error) {
+	key := c.Key(opts)
+	entry, err := c.store.Get(key)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading cached result: %w", err)
+	}
+
+	if entry == nil {
+		return nil, nil
+	}
+
+	if c.ttl > 0 && c.now().Sub(entry.StoredAt) > c.ttl {
+		if err := c.store.Delete(key); err != nil {
+			return nil, fmt.Errorf("Error deleting expired result: %w", err)
+		}
+		return nil, nil
+	}
+
+	return entry.Result, nil
+}
+
+func (c *ResultCache) Put(opts *SubmitCheckOptions, result *CheckResult) error {
+	entry := &CachedResult{Result: result, StoredAt: c.now()}
+	if err := c.store.Put(c.Key(opts), entry); err != nil {
+		return fmt.Errorf("Error caching result: %w", err)
+	}
+
+	return nil
+}
+
+// Invalidate removes the cached result for opts.
+func (c *ResultCache) Invalidate(opts *SubmitCheckOptions) error {
+	if err := c.store.Delete(c.Key(opts)); err != nil {
+		return fmt.Errorf("Error invalidating cached result: %w", err)
+	}
+
+	return nil
+}
+
+// InvalidateAll removes all cached results.
+func (c *ResultCache) InvalidateAll() error {
+	if err := c.store.Clear(); err != nil {
+		return fmt.Errorf("Error invalidating cached results: %w", err)
+	}
+
+	return nil
+}
+
+// Wrap returns a Checker that serves results from the cache and only
+// delegates to next for content it has not seen before.
+func (c *ResultCache) Wrap(next Checker) Checker {
+	return &cachingChecker{cache: c, next: next}
+}
+
+type cachingChecker struct {
+	cache *ResultCache
+	next  Checker
+}
+
+func (c *cachingChecker) Check(ctx context.Context, opts *SubmitCheckOptions) (*CheckResult, error) {
+	result, err := c.cache.Get(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if result != nil {
+		return result, nil
+	}
+
+	result, err = c.next.Check(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Progress == nil {
+		if err := c.cache.Put(opts, result); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// MemoryResultStore keeps results in memory.
+type MemoryResultStore struct {
+	mu      sync.Mutex
+	entries map[string]*CachedResult
+}
+
+func NewMemoryResultStore() *MemoryResultStore {
+	return &MemoryResultStore{entries: make(map[string]*CachedResult)}
+}
+
+func (s *MemoryResultStore) Get(key string) (*CachedResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.entries[key], nil
+}
+
+func (s *MemoryResultStore) Put(key string, entry *CachedResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[key] = entry
+	return nil
+}
+
+func (s *MemoryResultStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+func (s *MemoryResultStore) Clear() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries = make(map[string]*CachedResult)
+	return nil
+}
+
+// dirResultPrefix starts the names of the files of a DirResultStore, so
+// the directory can be shared with other files.
+const dirResultPrefix = "acrolinx-result-"
+
+// DirResultStore keeps results as JSON files in a directory, so they
+// survive between runs, e.g. in a CI cache. Only files named like its
+// entries are touched.
+type DirResultStore struct {
+	dir string
+}
+
+func NewDirResultStore(dir string) (*DirResultStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("Error creating cache directory: %w", err)
+	}
+
+	return &DirResultStore{dir}, nil
+}
+
+func (s *DirResultStore) path(key string) string {
+	return filepath.Join(s.dir, dirResultPrefix+key+".json")
+}
+
+func (s *DirResultStore) Get(key string) (*CachedResult, error) {
+	data, err := os.ReadFile(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entry CachedResult
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("Error decoding JSON: %w", err)
+	}
+
+	return &entry, nil
+}
+
+func (s *DirResultStore) Put(key string, entry *CachedResult) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("Error encoding JSON: %w", err)
+	}
+
+	f, err := os.CreateTemp(s.dir, dirResultPrefix+key+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	return os.Rename(f.Name(), s.path(key))
+}
+
+func (s *DirResultStore) Delete(key string) error {
+	err := os.Remove(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+// Clear removes all entries, along with the temporary files of
+// interrupted writes.
+func (s *DirResultStore) Clear() error {
+	var paths []string
+	for _, pattern := range []string{"*.json", "*.tmp"} {
+		matches, err := filepath.Glob(filepath.Join(s.dir, dirResultPrefix+pattern))
+		if err != nil {
+			return err
+		}
+		paths = append(paths, matches...)
+	}
+
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	return nil
+}
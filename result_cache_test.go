@@ -0,0 +1,180 @@
+package acrolinx
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeChecker struct {
+	calls  int
+	result *CheckResult
+	err    error
+}
+
+func (f *fakeChecker) Check(ctx context.Context, opts *SubmitCheckOptions) (*CheckResult, error) {
+	f.calls++
+	return f.result, f.err
+}
+
+func cacheTestOptions(content string) *SubmitCheckOptions {
+	return &SubmitCheckOptions{
+		Content: content,
+		CheckOptions: &CheckOptions{
+			GuidanceProfileID: "profile",
+			ContentFormat:     "MARKDOWN",
+		},
+	}
+}
+
+func TestResultCacheKey(t *testing.T) {
+	cache := NewResultCache(NewMemoryResultStore(), nil)
+	key := cache.Key(cacheTestOptions("Some text"))
+
+	assert.Equal(t, key, cache.Key(cacheTestOptions("Some text")))
+	assert.NotEqual(t, key, cache.Key(cacheTestOptions("Other text")))
+
+	otherFormat := cacheTestOptions("Some text")
+	otherFormat.CheckOptions.ContentFormat = "TEXT"
+	assert.NotEqual(t, key, cache.Key(otherFormat))
+
+	otherProfile := cacheTestOptions("Some text")
+	otherProfile.CheckOptions.GuidanceProfileID = "other"
+	assert.NotEqual(t, key, cache.Key(otherProfile))
+
+	otherCheckType := cacheTestOptions("Some text")
+	otherCheckType.CheckOptions.CheckType = "baseline"
+	assert.NotEqual(t, key, cache.Key(otherCheckType))
+
+	otherDocument := cacheTestOptions("Some text")
+	otherDocument.Document = &Document{Reference: "a.md", CustomFields: []*CustomField{{Key: "team", Value: "docs"}}}
+	assert.NotEqual(t, key, cache.Key(otherDocument))
+
+	otherFields := cacheTestOptions("Some text")
+	otherFields.Document = &Document{Reference: "a.md", CustomFields: []*CustomField{{Key: "team", Value: "dev"}}}
+	assert.NotEqual(t, cache.Key(otherDocument), cache.Key(otherFields))
+
+	withReports := cacheTestOptions("Some text")
+	withReports.CheckOptions.ReportTypes = []string{"scorecard", "termHarvesting"}
+	assert.NotEqual(t, key, cache.Key(withReports))
+
+	reordered := cacheTestOptions("Some text")
+	reordered.CheckOptions.ReportTypes = []string{"termHarvesting", "scorecard"}
+	assert.Equal(t, cache.Key(withReports), cache.Key(reordered))
+
+	otherCaps := NewResultCache(NewMemoryResultStore(), &ResultCacheOptions{
+		Capabilities: &Capabilities{DefaultGuidanceProfileID: "profile"},
+	})
+	assert.NotEqual(t, key, otherCaps.Key(cacheTestOptions("Some text")))
+}
+
+func TestResultCacheWrap(t *testing.T) {
+	cache := NewResultCache(NewMemoryResultStore(), nil)
+	next := &fakeChecker{result: &CheckResult{ID: "check"}}
+	checker := cache.Wrap(next)
+
+	for i := 0; i < 3; i++ {
+		result, err := checker.Check(context.Background(), cacheTestOptions("Some text"))
+		assert.NoError(t, err)
+		assert.Equal(t, "check", result.ID)
+	}
+	assert.Equal(t, 1, next.calls)
+
+	assert.NoError(t, cache.Invalidate(cacheTestOptions("Some text")))
+	_, err := checker.Check(context.Background(), cacheTestOptions("Some text"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, next.calls)
+}
+
+func TestResultCacheMissOnReportTypes(t *testing.T) {
+	cache := NewResultCache(NewMemoryResultStore(), nil)
+	next := &fakeChecker{result: &CheckResult{ID: "check"}}
+	checker := cache.Wrap(next)
+
+	_, err := checker.Check(context.Background(), cacheTestOptions("Some text"))
+	assert.NoError(t, err)
+
+	withReports := cacheTestOptions("Some text")
+	withReports.CheckOptions.ReportTypes = []string{"scorecard"}
+	_, err = checker.Check(context.Background(), withReports)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, next.calls)
+
+	_, err = checker.Check(context.Background(), withReports)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, next.calls)
+}
+
+func TestResultCacheWrapWithError(t *testing.T) {
+	cache := NewResultCache(NewMemoryResultStore(), nil)
+	next := &fakeChecker{err: errors.New("platform down")}
+
+	_, err := cache.Wrap(next).Check(context.Background(), cacheTestOptions("Some text"))
+	assert.EqualError(t, err, "platform down")
+
+	result, err := cache.Get(cacheTestOptions("Some text"))
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+}
+
+func TestResultCacheTTL(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := NewResultCache(NewMemoryResultStore(), &ResultCacheOptions{TTL: time.Hour})
+	cache.now = func() time.Time { return now }
+
+	opts := cacheTestOptions("Some text")
+	assert.NoError(t, cache.Put(opts, &CheckResult{ID: "check"}))
+
+	now = now.Add(30 * time.Minute)
+	result, err := cache.Get(opts)
+	assert.NoError(t, err)
+	assert.Equal(t, "check", result.ID)
+
+	now = now.Add(time.Hour)
+	result, err = cache.Get(opts)
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+}
+
+func TestDirResultStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewDirResultStore(dir)
+	assert.NoError(t, err)
+
+	cache := NewResultCache(store, nil)
+	opts := cacheTestOptions("Some text")
+
+	result, err := cache.Get(opts)
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+
+	assert.NoError(t, cache.Put(opts, &CheckResult{ID: "check", Quality: &Quality{Score: 80}}))
+
+	result, err = cache.Get(opts)
+	assert.NoError(t, err)
+	assert.Equal(t, &CheckResult{ID: "check", Quality: &Quality{Score: 80}}, result)
+
+	// Clearing removes entries and leftover temporary files, but not the
+	// other files of the directory.
+	other := filepath.Join(dir, "package.json")
+	tmp := filepath.Join(dir, dirResultPrefix+"key.123.tmp")
+	for _, p := range []string{other, tmp} {
+		assert.NoError(t, os.WriteFile(p, []byte("{}"), 0o644))
+	}
+
+	assert.NoError(t, cache.InvalidateAll())
+	result, err = cache.Get(opts)
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+
+	assert.FileExists(t, other)
+	assert.NoFileExists(t, tmp)
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
@@ -0,0 +1,290 @@
+package acrolinx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+type resultReaderState int
+
+const (
+	resultReaderStart resultReaderState = iota
+	resultReaderTop
+	resultReaderData
+	resultReaderIssues
+	resultReaderDone
+)
+
+// CheckResultReader decodes a check result response token by token.
+// Issues are handed out one at a time by Next instead of being
+// collected in CheckResult.Issues, so the memory needed to process a
+// result doesn't grow with the number of issues.
+type CheckResultReader struct {
+	dec   *json.Decoder
+	state resultReaderState
+
+	result   CheckResult
+	links    Links
+	progress Progress
+	reqError RequestError
+}
+
+func NewCheckResultReader(r io.Reader) *CheckResultReader {
+	return &CheckResultReader{
+		dec:   json.NewDecoder(r),
+		links: make(Links),
+	}
+}
+
+// Next returns the next issue of the result. It returns io.EOF once
+// the whole response has been read, or the *RequestError if the
+// platform answered with an error.
+func (r *CheckResultReader) Next() (*Issue, error) {
+	for {
+		switch r.state {
+		case resultReaderStart:
+			if err := r.expectDelim('{'); err != nil {
+				return nil, err
+			}
+			r.state = resultReaderTop
+
+		case resultReaderTop:
+			key, ok, err := r.nextKey()
+			if err != nil {
+				return nil, err
+			}
+
+			if !ok {
+				r.state = resultReaderDone
+				continue
+			}
+
+			if key != "data" {
+				if err := r.decodeField(r.topField(key)); err != nil {
+					return nil, err
+				}
+				continue
+			}
+
+			isObject, err := r.enter('{')
+			if err != nil {
+				return nil, err
+			}
+			if isObject {
+				r.state = resultReaderData
+			}
+
+		case resultReaderData:
+			key, ok, err := r.nextKey()
+			if err != nil {
+				return nil, err
+			}
+
+			if !ok {
+				r.state = resultReaderTop
+				continue
+			}
+
+			if key != "issues" {
+				if err := r.decodeField(r.dataField(key)); err != nil {
+					return nil, err
+				}
+				continue
+			}
+
+			isArray, err := r.enter('[')
+			if err != nil {
+				return nil, err
+			}
+			if isArray {
+				r.state = resultReaderIssues
+			}
+
+		case resultReaderIssues:
+			if r.dec.More() {
+				var issue Issue
+				if err := r.dec.Decode(&issue); err != nil {
+					return nil, fmt.Errorf("Error decoding JSON response: %w", err)
+				}
+				return &issue, nil
+			}
+
+			if err := r.expectDelim(']'); err != nil {
+				return nil, err
+			}
+			r.state = resultReaderData
+
+		case resultReaderDone:
+			if r.reqError != (RequestError{}) {
+				return nil, &r.reqError
+			}
+			return nil, io.EOF
+		}
+	}
+}
+
+// Result returns everything but the issues of the check result. It is
+// complete once Next has returned io.EOF.
+func (r *CheckResultReader) Result() *CheckResult {
+	if r.progress != (Progress{}) {
+		r.result.Progress = &r.progress
+	}
+
+	return &r.result
+}
+
+func (r *CheckResultReader) Links() Links {
+	return r.links
+}
+
+func (r *CheckResultReader) topField(key string) interface{} {
+	switch key {
+	case "links":
+		return &r.links
+	case "progress":
+		return &r.progress
+	case "error":
+		return &r.reqError
+	}
+
+	return nil
+}
+
+func (r *CheckResultReader) dataField(key string) interface{} {
+	res := &r.result
+
+	switch key {
+	case "id":
+		return &res.ID
+	case "checkOptions":
+		return &res.CheckOptions
+	case "document":
+		return &res.Document
+	case "quality":
+		return &res.Quality
+	case "counts":
+		return &res.Counts
+	case "goals":
+		return &res.Goals
+	case "keywords":
+		return &res.Keywords
+	case "embed":
+		return &res.Embed
+	case "reports":
+		return &res.Reports
+	case "runtimeStatistics":
+		return &res.RuntimeStatistics
+	case "dictionaryScopes":
+		return &res.DictionaryScopes
+	}
+
+	return nil
+}
+
+// decodeField decodes the next value into v, or skips it if v is nil.
+func (r *CheckResultReader) decodeField(v interface{}) error {
+	if v == nil {
+		var skipped json.RawMessage
+		v = &skipped
+	}
+
+	if err := r.dec.Decode(v); err != nil {
+		return fmt.Errorf("Error decoding JSON response: %w", err)
+	}
+
+	return nil
+}
+
+// nextKey reads the next key of the current object. It returns false
+// and consumes the closing brace at the end of the object.
+func (r *CheckResultReader) nextKey() (string, bool, error) {
+	if !r.dec.More() {
+		return "", false, r.expectDelim('}')
+	}
+
+	tok, err := r.dec.Token()
+	if err != nil {
+		return "", false, fmt.Errorf("Error decoding JSON response: %w", err)
+	}
+
+	key, ok := tok.(string)
+	if !ok {
+		return "", false, fmt.Errorf("Error decoding JSON response: unexpected token %v", tok)
+	}
+
+	return key, true, nil
+}
+
+// enter consumes the opening delimiter of an object or array. It
+// returns false if the value is null instead.
+func (r *CheckResultReader) enter(delim json.Delim) (bool, error) {
+	tok, err := r.dec.Token()
+	if err != nil {
+		return false, fmt.Errorf("Error decoding JSON response: %w", err)
+	}
+
+	if tok == nil {
+		return false, nil
+	}
+
+	if tok != delim {
+		return false, fmt.Errorf("Error decoding JSON response: expected %v, got %v", delim, tok)
+	}
+
+	return true, nil
+}
+
+func (r *CheckResultReader) expectDelim(delim json.Delim) error {
+	tok, err := r.dec.Token()
+	if err != nil {
+		return fmt.Errorf("Error decoding JSON response: %w", err)
+	}
+
+	if tok != delim {
+		return fmt.Errorf("Error decoding JSON response: expected %v, got %v", delim, tok)
+	}
+
+	return nil
+}
+
+// ReadCheckResult reads a check result response from rd, calling fn
+// for every issue. Reading stops at the first error returned by fn.
+// The returned result has no issues.
+func ReadCheckResult(rd io.Reader, fn func(*Issue) error) (*CheckResult, Links, error) {
+	r := NewCheckResultReader(rd)
+
+	for {
+		issue, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return r.Result(), r.Links(), nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if err := fn(issue); err != nil {
+			return nil, nil, err
+		}
+	}
+}
+
+// StreamCheckResult gets the result of check like GetCheckResult, but
+// calls fn for every issue instead of collecting them in the result.
+func (s *CheckingService) StreamCheckResult(check *Check, fn func(*Issue) error) (*CheckResult, Links, error) {
+	path := fmt.Sprintf("api/v1/checking/checks/%s", check.ID)
+	req, err := s.client.newRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error preparing check request: %w", err)
+	}
+
+	res, err := s.client.client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error submitting request: %w", err)
+	}
+	defer res.Body.Close()
+
+	return ReadCheckResult(res.Body, fn)
+}
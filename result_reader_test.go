@@ -0,0 +1,244 @@
+package acrolinx
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadCheckResult(t *testing.T) {
+	f, err := os.Open("testdata/check_result.json")
+	assert.NoError(t, err)
+	defer f.Close()
+
+	var issues []*Issue
+	result, links, err := ReadCheckResult(f, func(issue *Issue) error {
+		issues = append(issues, issue)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	data, err := os.ReadFile("testdata/check_result.json")
+	assert.NoError(t, err)
+
+	var expected CheckResult
+	assert.NoError(t, json.Unmarshal(data, &Response{Data: &expected}))
+
+	assert.Equal(t, expected.Issues, issues)
+	expected.Issues = nil
+	assert.Equal(t, &expected, result)
+	assert.Equal(t, Links{}, links)
+}
+
+func TestCheckResultReaderWithProgress(t *testing.T) {
+	f, err := os.Open("testdata/progress.json")
+	assert.NoError(t, err)
+	defer f.Close()
+
+	r := NewCheckResultReader(f)
+	_, err = r.Next()
+	assert.Equal(t, io.EOF, err)
+
+	assert.Equal(t, &Progress{27, "Still processing in state ALLOCATED ...", 1}, r.Result().Progress)
+}
+
+func TestCheckResultReaderWithError(t *testing.T) {
+	f, err := os.Open("testdata/error.json")
+	assert.NoError(t, err)
+	defer f.Close()
+
+	_, _, err = ReadCheckResult(f, func(*Issue) error { return nil })
+	assert.EqualError(t, err, "Please provide a valid signature in the X-Acrolinx-Client header.")
+}
+
+func TestCheckResultReaderWithNullIssues(t *testing.T) {
+	r := NewCheckResultReader(strings.NewReader(`{"data":{"issues":null,"id":"check","unknown":[1,2]},"links":{"self":"url"}}`))
+
+	_, err := r.Next()
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, "check", r.Result().ID)
+	assert.Equal(t, Links{"self": "url"}, r.Links())
+}
+
+func TestCheckResultReaderWithInvalidJSON(t *testing.T) {
+	_, _, err := ReadCheckResult(strings.NewReader(`{"data":{"issues":[{]}}`), func(*Issue) error { return nil })
+	assert.ErrorContains(t, err, "Error decoding JSON response")
+}
+
+func TestReadCheckResultCallbackError(t *testing.T) {
+	f, err := os.Open("testdata/check_result.json")
+	assert.NoError(t, err)
+	defer f.Close()
+
+	stop := errors.New("stop")
+	_, _, err = ReadCheckResult(f, func(*Issue) error { return stop })
+	assert.Equal(t, stop, err)
+}
+
+func TestStreamCheckResult(t *testing.T) {
+	mux, server, client := setup(t)
+	defer teardown(server)
+
+	mux.HandleFunc("/api/v1/checking/checks/052929ee-be0c-46a7-87ce-eebd308fef6e",
+		func(w http.ResponseWriter, r *http.Request) {
+			testMethod(t, r, http.MethodGet)
+			mustWriteHTTPResponse(t, w, "check_result.json")
+		})
+
+	count := 0
+	check := &Check{"052929ee-be0c-46a7-87ce-eebd308fef6e"}
+	result, _, err := client.Checking.StreamCheckResult(check, func(issue *Issue) error {
+		count++
+		assert.Equal(t, "CLARITY", issue.GoalID)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, count)
+	assert.Equal(t, 74, result.Quality.Score)
+	assert.Equal(t, 6, result.Counts.Issues)
+	assert.Len(t, result.Goals, 2)
+	assert.Nil(t, result.Issues)
+}
+
+func largeCheckResult(issues int) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(`{"links":{},"data":{"id":"check","quality":{"score":50,"status":"red"},"counts":{"issues":`)
+	fmt.Fprint(&buf, issues)
+	buf.WriteString(`},"goals":[{"id":"CLARITY","displayName":"Clarity"}],"issues":[`)
+	for i := 0; i < issues; i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		fmt.Fprintf(&buf, `{"goalId":"CLARITY","internalName":"rule_%d","displayNameHtml":"<div>Rule</div>",`+
+			`"guidanceHtml":"<div>Some lengthy guidance explaining the rule in detail.</div>",`+
+			`"positionalInformation":{"hashes":{"issue":"abc","environment":"def","index":"ghi"},`+
+			`"matches":[{"originalPart":"word","originalBegin":%d,"originalEnd":%d}]},`+
+			`"suggestions":[{"surface":"term","replacements":["term"]}]}`, i, i*10, i*10+4)
+	}
+	buf.WriteString(`]}}`)
+	return buf.Bytes()
+}
+
+// heapInUse reports the live heap after a garbage collection.
+func heapInUse() uint64 {
+	var stats runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&stats)
+	return stats.HeapAlloc
+}
+
+// peakHeap tracks the high-water mark of the heap above the live heap
+// when it was created. Garbage not yet collected counts as well, so
+// measurements run with a low GC target to collect it promptly.
+type peakHeap struct {
+	base     uint64
+	peak     uint64
+	gcTarget int
+}
+
+func newPeakHeap() *peakHeap {
+	gcTarget := debug.SetGCPercent(10)
+	base := heapInUse()
+	return &peakHeap{base: base, peak: base, gcTarget: gcTarget}
+}
+
+func (p *peakHeap) sample() {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	p.peak = max(p.peak, stats.HeapAlloc)
+}
+
+// done stops measuring and returns the peak.
+func (p *peakHeap) done() uint64 {
+	debug.SetGCPercent(p.gcTarget)
+	return p.peak - p.base
+}
+
+// decodePeak decodes data in full and returns the peak heap. It is only
+// sampled after decoding, so it is a lower bound.
+func decodePeak(tb testing.TB, data []byte) uint64 {
+	p := newPeakHeap()
+
+	var result CheckResult
+	if err := json.NewDecoder(bytes.NewReader(data)).Decode(&Response{Data: &result}); err != nil {
+		tb.Fatal(err)
+	}
+	p.sample()
+	runtime.KeepAlive(result)
+
+	return p.done()
+}
+
+// readPeak streams the issues of data and returns the peak heap,
+// sampled every 100 issues.
+func readPeak(tb testing.TB, data []byte) uint64 {
+	p := newPeakHeap()
+
+	count := 0
+	result, _, err := ReadCheckResult(bytes.NewReader(data), func(*Issue) error {
+		count++
+		if count%100 == 0 {
+			p.sample()
+		}
+		return nil
+	})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	p.sample()
+	runtime.KeepAlive(result)
+
+	return p.done()
+}
+
+func TestReadCheckResultPeakMemory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping memory measurement in short mode")
+	}
+
+	data := largeCheckResult(20000)
+	full := decodePeak(t, data)
+	streamed := readPeak(t, data)
+	t.Logf("peak heap: full decode %d B, streamed %d B", full, streamed)
+
+	// Streaming keeps one issue at a time, so its peak doesn't grow
+	// with the number of issues.
+	assert.Less(t, streamed, full/4)
+}
+
+func BenchmarkDecodeCheckResult(b *testing.B) {
+	data := largeCheckResult(20000)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	var peak uint64
+	for i := 0; i < b.N; i++ {
+		peak = max(peak, decodePeak(b, data))
+	}
+
+	b.ReportMetric(float64(peak), "peak-B")
+}
+
+func BenchmarkReadCheckResult(b *testing.B) {
+	data := largeCheckResult(20000)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	var peak uint64
+	for i := 0; i < b.N; i++ {
+		peak = max(peak, readPeak(b, data))
+	}
+
+	b.ReportMetric(float64(peak), "peak-B")
+}
@@ -0,0 +1,167 @@
+package acrolinx
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// RDJSONResult is a report in the reviewdog diagnostic format (rdjson).
+type RDJSONResult struct {
+	Source      *RDSource       `json:"source"`
+	Severity    string          `json:"severity,omitempty"`
+	Diagnostics []*RDDiagnostic `json:"diagnostics"`
+}
+
+type RDSource struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+type RDDiagnostic struct {
+	Message     string          `json:"message"`
+	Location    *RDLocation     `json:"location"`
+	Severity    string          `json:"severity"`
+	Source      *RDSource       `json:"source,omitempty"`
+	Code        *RDCode         `json:"code,omitempty"`
+	Suggestions []*RDSuggestion `json:"suggestions,omitempty"`
+}
+
+type RDLocation struct {
+	Path  string   `json:"path"`
+	Range *RDRange `json:"range,omitempty"`
+}
+
+// RDRange is a range in a file. Columns count UTF-8 bytes, the end is
+// exclusive.
+type RDRange struct {
+	Start *RDPosition `json:"start"`
+	End   *RDPosition `json:"end,omitempty"`
+}
+
+type RDPosition struct {
+	Line   int `json:"line"`
+	Column int `json:"column,omitempty"`
+}
+
+type RDCode struct {
+	Value string `json:"value"`
+	URL   string `json:"url,omitempty"`
+}
+
+type RDSuggestion struct {
+	Range *RDRange `json:"range"`
+	Text  string   `json:"text"`
+}
+
+var rdSource = &RDSource{Name: "acrolinx", URL: "https://www.acrolinx.com"}
+
+// NewRDJSON converts the issues of docs into reviewdog diagnostics.
+// Every applicable suggestion of an issue becomes a suggestion
+// replacing the range spanned by its edits.
+func NewRDJSON(docs []*CheckedDocument) *RDJSONResult {
+	result := &RDJSONResult{
+		Source:      rdSource,
+		Diagnostics: []*RDDiagnostic{},
+	}
+
+	for _, doc := range sortedDocuments(docs) {
+		x := NewPositionIndex(doc.Content)
+
+		for _, issue := range doc.locatedIssues() {
+			d := &RDDiagnostic{
+				Message:  issueTitle(issue.Issue),
+				Location: &RDLocation{Path: doc.slashPath()},
+				Severity: rdSeverity(doc.severity(issue.Issue)),
+				Code:     &RDCode{Value: issueRuleID(issue.Issue), URL: issue.Links["help"]},
+			}
+
+			if issue.Located {
+				d.Location.Range = rdRange(issue.Range)
+			}
+
+			for _, s := range issue.Suggestions {
+				edits, err := fixEdits(x, doc.Content, &Fix{issue.Issue, s})
+				if err != nil {
+					continue
+				}
+				d.Suggestions = append(d.Suggestions, rdSuggestion(x, doc.Content, edits))
+			}
+
+			result.Diagnostics = append(result.Diagnostics, d)
+		}
+	}
+
+	return result
+}
+
+// WriteRDJSON writes the rdjson report for docs to w.
+func WriteRDJSON(w io.Writer, docs []*CheckedDocument) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(NewRDJSON(docs)); err != nil {
+		return fmt.Errorf("Error writing rdjson report: %w", err)
+	}
+
+	return nil
+}
+
+// WriteRDJSONL writes the diagnostics for docs to w in the rdjsonl
+// format, one diagnostic per line.
+func WriteRDJSONL(w io.Writer, docs []*CheckedDocument) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+
+	for _, d := range NewRDJSON(docs).Diagnostics {
+		d.Source = rdSource
+		if err := enc.Encode(d); err != nil {
+			return fmt.Errorf("Error writing rdjsonl report: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func rdSeverity(severity string) string {
+	switch severity {
+	case SeverityError:
+		return "ERROR"
+	case SeverityWarning:
+		return "WARNING"
+	}
+
+	return "INFO"
+}
+
+func rdRange(r Range) *RDRange {
+	return &RDRange{
+		Start: &RDPosition{Line: r.Start.Line, Column: r.Start.ByteColumn},
+		End:   &RDPosition{Line: r.End.Line, Column: r.End.ByteColumn},
+	}
+}
+
+// rdSuggestion merges the edits of a suggestion into a single
+// replacement of the text from the first to the last edit.
+func rdSuggestion(x *PositionIndex, content string, edits []*Edit) *RDSuggestion {
+	edits = append([]*Edit(nil), edits...)
+	sort.Slice(edits, func(i, j int) bool {
+		return edits[i].byteBegin < edits[j].byteBegin
+	})
+
+	var b strings.Builder
+	pos := edits[0].byteBegin
+	for _, e := range edits {
+		b.WriteString(content[pos:e.byteBegin])
+		b.WriteString(e.Replacement)
+		pos = e.byteEnd
+	}
+
+	last := edits[len(edits)-1]
+	return &RDSuggestion{
+		Range: rdRange(Range{x.FromUTF16(edits[0].Begin), x.FromUTF16(last.End)}),
+		Text:  b.String(),
+	}
+}
@@ -0,0 +1,41 @@
+package acrolinx
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteRDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, WriteRDJSON(&buf, reportTestDocuments()))
+	assertGolden(t, "report.rdjson", buf.String())
+}
+
+func TestWriteRDJSONL(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, WriteRDJSONL(&buf, reportTestDocuments()))
+	assertGolden(t, "report.rdjsonl", buf.String())
+}
+
+func TestRDJSONSuggestions(t *testing.T) {
+	result := NewRDJSON(reportTestDocuments())
+	assert.Len(t, result.Diagnostics, 4)
+
+	simpler := result.Diagnostics[2]
+	assert.Equal(t, "simpler_word", simpler.Code.Value)
+	assert.Len(t, simpler.Suggestions, 2)
+	assert.Equal(t, "employ", simpler.Suggestions[1].Text)
+
+	// The edits of the contraction are merged into one replacement,
+	// columns count bytes.
+	contraction := result.Diagnostics[3]
+	assert.Equal(t, &RDSuggestion{
+		Range: &RDRange{
+			Start: &RDPosition{Line: 4, Column: 16},
+			End:   &RDPosition{Line: 4, Column: 22},
+		},
+		Text: "isn’t",
+	}, contraction.Suggestions[0])
+}
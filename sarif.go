@@ -0,0 +1,269 @@
+package acrolinx
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+type SARIFLog struct {
+	Schema  string      `json:"$schema"`
+	Version string      `json:"version"`
+	Runs    []*SARIFRun `json:"runs"`
+}
+
+type SARIFRun struct {
+	Tool       *SARIFTool     `json:"tool"`
+	Results    []*SARIFResult `json:"results"`
+	ColumnKind string         `json:"columnKind"`
+}
+
+type SARIFTool struct {
+	Driver *SARIFDriver `json:"driver"`
+}
+
+type SARIFDriver struct {
+	Name           string       `json:"name"`
+	InformationURI string       `json:"informationUri,omitempty"`
+	Rules          []*SARIFRule `json:"rules"`
+}
+
+type SARIFRule struct {
+	ID                   string                  `json:"id"`
+	ShortDescription     *SARIFMessage           `json:"shortDescription,omitempty"`
+	Help                 *SARIFMessage           `json:"help,omitempty"`
+	HelpURI              string                  `json:"helpUri,omitempty"`
+	DefaultConfiguration *SARIFRuleConfiguration `json:"defaultConfiguration,omitempty"`
+	Properties           map[string]interface{}  `json:"properties,omitempty"`
+}
+
+type SARIFRuleConfiguration struct {
+	Level string `json:"level"`
+}
+
+type SARIFMessage struct {
+	Text     string `json:"text"`
+	Markdown string `json:"markdown,omitempty"`
+}
+
+type SARIFResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             *SARIFMessage     `json:"message"`
+	Locations           []*SARIFLocation  `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+	Fixes               []*SARIFFix       `json:"fixes,omitempty"`
+}
+
+type SARIFLocation struct {
+	PhysicalLocation *SARIFPhysicalLocation `json:"physicalLocation"`
+}
+
+type SARIFPhysicalLocation struct {
+	ArtifactLocation *SARIFArtifactLocation `json:"artifactLocation"`
+	Region           *SARIFRegion           `json:"region,omitempty"`
+}
+
+type SARIFArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type SARIFRegion struct {
+	StartLine   int                   `json:"startLine"`
+	StartColumn int                   `json:"startColumn"`
+	EndLine     int                   `json:"endLine"`
+	EndColumn   int                   `json:"endColumn"`
+	CharOffset  int                   `json:"charOffset"`
+	CharLength  int                   `json:"charLength"`
+	Snippet     *SARIFArtifactContent `json:"snippet,omitempty"`
+}
+
+type SARIFArtifactContent struct {
+	Text string `json:"text"`
+}
+
+type SARIFFix struct {
+	Description     *SARIFMessage          `json:"description"`
+	ArtifactChanges []*SARIFArtifactChange `json:"artifactChanges"`
+}
+
+type SARIFArtifactChange struct {
+	ArtifactLocation *SARIFArtifactLocation `json:"artifactLocation"`
+	Replacements     []*SARIFReplacement    `json:"replacements"`
+}
+
+type SARIFReplacement struct {
+	DeletedRegion   *SARIFRegion          `json:"deletedRegion"`
+	InsertedContent *SARIFArtifactContent `json:"insertedContent"`
+}
+
+// NewSARIFLog converts the results of checking docs into a SARIF log
+// with a single run. Columns and character offsets are counted in
+// UTF-16 code units.
+func NewSARIFLog(docs []*CheckedDocument) *SARIFLog {
+	driver := &SARIFDriver{
+		Name:           "Acrolinx",
+		InformationURI: "https://www.acrolinx.com",
+		Rules:          []*SARIFRule{},
+	}
+	run := &SARIFRun{
+		Tool:       &SARIFTool{driver},
+		Results:    []*SARIFResult{},
+		ColumnKind: "utf16CodeUnits",
+	}
+	ruleIndexes := make(map[string]int)
+
+	for _, doc := range docs {
+		x := NewPositionIndex(doc.Content)
+		artifact := &SARIFArtifactLocation{URI: doc.slashPath()}
+
+		for _, issue := range doc.locatedIssues() {
+			level := sarifLevel(doc.severity(issue.Issue))
+
+			ruleID := issueRuleID(issue.Issue)
+			index, ok := ruleIndexes[ruleID]
+			if !ok {
+				index = len(driver.Rules)
+				ruleIndexes[ruleID] = index
+				driver.Rules = append(driver.Rules, newSARIFRule(ruleID, issue.Issue, level))
+			}
+
+			result := &SARIFResult{
+				RuleID:    ruleID,
+				RuleIndex: index,
+				Level:     level,
+				Message:   &SARIFMessage{Text: issueTitle(issue.Issue)},
+				Locations: []*SARIFLocation{{
+					PhysicalLocation: &SARIFPhysicalLocation{ArtifactLocation: artifact},
+				}},
+				PartialFingerprints: sarifFingerprints(issue.PositionalInformation),
+				Fixes:               sarifFixes(x, doc.Content, artifact, issue.Issue),
+			}
+
+			if issue.Located {
+				region := sarifRegion(issue.Range)
+				region.Snippet = &SARIFArtifactContent{doc.Content[issue.Range.Start.Byte:issue.Range.End.Byte]}
+				result.Locations[0].PhysicalLocation.Region = region
+			}
+
+			run.Results = append(run.Results, result)
+		}
+	}
+
+	return &SARIFLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []*SARIFRun{run},
+	}
+}
+
+// WriteSARIF writes the SARIF log for docs to w.
+func WriteSARIF(w io.Writer, docs []*CheckedDocument) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(NewSARIFLog(docs)); err != nil {
+		return fmt.Errorf("Error writing SARIF log: %w", err)
+	}
+
+	return nil
+}
+
+func newSARIFRule(id string, issue *Issue, level string) *SARIFRule {
+	rule := &SARIFRule{
+		ID:                   id,
+		ShortDescription:     &SARIFMessage{Text: issueTitle(issue)},
+		HelpURI:              issue.Links["help"],
+		DefaultConfiguration: &SARIFRuleConfiguration{Level: level},
+	}
+
+	if guidance := htmlToText(issue.GuidanceHTML); guidance != "" {
+		rule.Help = &SARIFMessage{Text: guidance}
+	}
+
+	if issue.GoalID != "" {
+		rule.Properties = map[string]interface{}{
+			"tags": []string{issue.GoalID},
+		}
+	}
+
+	return rule
+}
+
+func sarifLevel(severity string) string {
+	switch severity {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	}
+
+	return "note"
+}
+
+func sarifRegion(r Range) *SARIFRegion {
+	return &SARIFRegion{
+		StartLine:   r.Start.Line,
+		StartColumn: r.Start.UTF16Column,
+		EndLine:     r.End.Line,
+		EndColumn:   r.End.UTF16Column,
+		CharOffset:  r.Start.UTF16,
+		CharLength:  r.End.UTF16 - r.Start.UTF16,
+	}
+}
+
+func sarifFingerprints(info *PositionalInformation) map[string]string {
+	if info == nil || info.Hashes == nil {
+		return nil
+	}
+
+	fingerprints := make(map[string]string)
+	if info.Hashes.Issue != "" {
+		fingerprints["acrolinxIssueHash/v1"] = info.Hashes.Issue
+	}
+	if info.Hashes.Environment != "" {
+		fingerprints["acrolinxEnvironmentHash/v1"] = info.Hashes.Environment
+	}
+	if info.Hashes.Index != "" {
+		fingerprints["acrolinxIndexHash/v1"] = info.Hashes.Index
+	}
+
+	if len(fingerprints) == 0 {
+		return nil
+	}
+
+	return fingerprints
+}
+
+// sarifFixes turns every applicable suggestion of issue into a fix.
+func sarifFixes(x *PositionIndex, content string, artifact *SARIFArtifactLocation, issue *Issue) []*SARIFFix {
+	var fixes []*SARIFFix
+
+	for _, s := range issue.Suggestions {
+		edits, err := fixEdits(x, content, &Fix{issue, s})
+		if err != nil {
+			continue
+		}
+
+		change := &SARIFArtifactChange{ArtifactLocation: artifact}
+		for _, e := range edits {
+			change.Replacements = append(change.Replacements, &SARIFReplacement{
+				DeletedRegion:   sarifRegion(Range{x.FromUTF16(e.Begin), x.FromUTF16(e.End)}),
+				InsertedContent: &SARIFArtifactContent{e.Replacement},
+			})
+		}
+
+		fixes = append(fixes, &SARIFFix{
+			Description:     &SARIFMessage{Text: fmt.Sprintf("Replace with %q", s.Surface)},
+			ArtifactChanges: []*SARIFArtifactChange{change},
+		})
+	}
+
+	return fixes
+}
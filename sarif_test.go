@@ -0,0 +1,49 @@
+package acrolinx
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteSARIF(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, WriteSARIF(&buf, reportTestDocuments()))
+
+	assertGolden(t, "report.sarif", buf.String())
+}
+
+func TestNewSARIFLog(t *testing.T) {
+	log := NewSARIFLog(reportTestDocuments())
+
+	assert.Equal(t, "2.1.0", log.Version)
+	assert.Len(t, log.Runs, 1)
+
+	run := log.Runs[0]
+	assert.Len(t, run.Tool.Driver.Rules, 4)
+	assert.Len(t, run.Results, 4)
+
+	contraction := run.Results[3]
+	assert.Equal(t, "contraction", contraction.RuleID)
+	assert.Equal(t, "warning", contraction.Level)
+	assert.Equal(t, "Use the contraction isn’t.", contraction.Message.Text)
+	assert.Equal(t, "aXNzdWUtMw==", contraction.PartialFingerprints["acrolinxIssueHash/v1"])
+
+	region := contraction.Locations[0].PhysicalLocation.Region
+	assert.Equal(t, 4, region.StartLine)
+	assert.Equal(t, 8, region.StartColumn)
+	assert.Equal(t, 14, region.EndColumn)
+	assert.Equal(t, "is not", region.Snippet.Text)
+
+	assert.Len(t, contraction.Fixes, 1)
+	assert.Len(t, contraction.Fixes[0].ArtifactChanges[0].Replacements, 3)
+
+	simpler := run.Results[2]
+	assert.Equal(t, "error", simpler.Level)
+	assert.Len(t, simpler.Fixes, 2)
+	assert.Equal(t, "https://example.com/help/simpler_word", run.Tool.Driver.Rules[simpler.RuleIndex].HelpURI)
+	assert.Equal(t, "Simple words are easier to read & understand.", run.Tool.Driver.Rules[simpler.RuleIndex].Help.Text)
+
+	assert.Nil(t, run.Results[0].Locations[0].PhysicalLocation.Region)
+}
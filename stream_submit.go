@@ -0,0 +1,152 @@
+package acrolinx
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const hexDigits = "0123456789abcdef"
+
+type StreamOptions struct {
+	// Gzip compresses the request body. Only use it if the platform
+	// accepts gzip encoded requests.
+	Gzip bool
+}
+
+// SubmitCheckFromReader submits a check like SubmitCheck, but reads the
+// content from content while the request is sent instead of taking it
+// from opts.Content. Memory use stays bounded no matter how large the
+// document is. If opts.ContentEncoding is ContentEncodingBase64, the
+// content is base64 encoded on the fly.
+func (s *CheckingService) SubmitCheckFromReader(content io.Reader, opts *SubmitCheckOptions, streamOpts *StreamOptions) (*Check, Links, error) {
+	head, tail, err := checkEnvelope(opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error preparing check request: %w", err)
+	}
+
+	compress := streamOpts != nil && streamOpts.Gzip
+	base64Content := opts.ContentEncoding == ContentEncodingBase64
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(writeCheckEnvelope(pw, content, head, tail, base64Content, compress))
+	}()
+	defer pr.Close()
+
+	req, err := s.client.newRequestWithBody(http.MethodPost, "api/v1/checking/checks", pr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error preparing check request: %w", err)
+	}
+
+	if compress {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	var check Check
+	links := make(Links)
+	var reqError RequestError
+	resp := Response{Data: &check, Links: links, Error: &reqError}
+	err = s.client.do(req, &resp)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error processing check request: %w", err)
+	}
+
+	if reqError != (RequestError{}) {
+		return nil, nil, &reqError
+	}
+
+	return &check, links, nil
+}
+
+// checkEnvelope returns the JSON encoding of opts split around the
+// value of the content string.
+func checkEnvelope(opts *SubmitCheckOptions) ([]byte, []byte, error) {
+	envelope := *opts
+	envelope.Content = ""
+
+	data, err := json.Marshal(&envelope)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error encoding JSON: %w", err)
+	}
+
+	head := []byte(`{"content":"`)
+	if !bytes.HasPrefix(data, head) {
+		return nil, nil, fmt.Errorf("Error encoding JSON: unexpected envelope %s", data)
+	}
+
+	return head, data[len(head):], nil
+}
+
+func writeCheckEnvelope(w io.Writer, content io.Reader, head, tail []byte, base64Content, compress bool) error {
+	var gz *gzip.Writer
+	if compress {
+		gz = gzip.NewWriter(w)
+		w = gz
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.Write(head)
+
+	if base64Content {
+		enc := base64.NewEncoder(base64.StdEncoding, bw)
+		if _, err := io.Copy(enc, content); err != nil {
+			return fmt.Errorf("Error reading content: %w", err)
+		}
+		enc.Close()
+	} else if err := copyJSONString(bw, bufio.NewReader(content)); err != nil {
+		return err
+	}
+
+	bw.Write(tail)
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+
+	if gz != nil {
+		return gz.Close()
+	}
+
+	return nil
+}
+
+// copyJSONString writes the text read from r to w, escaped for use
+// inside a JSON string the same way encoding/json escapes it.
+func copyJSONString(w *bufio.Writer, r *bufio.Reader) error {
+	for {
+		c, _, err := r.ReadRune()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("Error reading content: %w", err)
+		}
+
+		switch {
+		case c == '"' || c == '\\':
+			w.WriteByte('\\')
+			w.WriteByte(byte(c))
+		case c == '\n':
+			w.WriteString(`\n`)
+		case c == '\r':
+			w.WriteString(`\r`)
+		case c == '\t':
+			w.WriteString(`\t`)
+		case c < 0x20 || c == '<' || c == '>' || c == '&':
+			w.WriteString(`\u00`)
+			w.WriteByte(hexDigits[c>>4])
+			w.WriteByte(hexDigits[c&0xf])
+		case c == '\u2028':
+			w.WriteString(`\u2028`)
+		case c == '\u2029':
+			w.WriteString(`\u2029`)
+		default:
+			w.WriteRune(c)
+		}
+	}
+}
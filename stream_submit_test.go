@@ -0,0 +1,114 @@
+package acrolinx
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCopyJSONString(t *testing.T) {
+	inputs := []string{
+		"",
+		"Plain text",
+		"Quotes \" and \\ backslashes",
+		"Line\nbreaks\r\nand\ttabs\x00\x1f",
+		"<p>Markup & entities</p>",
+		"Unicode: 日本語 😀 \u2028\u2029",
+		"Invalid UTF-8: \xff\xfe",
+	}
+
+	for _, input := range inputs {
+		var buf bytes.Buffer
+		w := bufio.NewWriter(&buf)
+		assert.NoError(t, copyJSONString(w, bufio.NewReader(strings.NewReader(input))))
+		assert.NoError(t, w.Flush())
+
+		expected, err := json.Marshal(input)
+		assert.NoError(t, err)
+		assert.Equal(t, string(expected), `"`+buf.String()+`"`)
+	}
+}
+
+func TestSubmitCheckFromReader(t *testing.T) {
+	mux, server, client := setup(t)
+	defer teardown(server)
+
+	content := strings.Repeat("A \"quoted\" line with ümlauts.\n", 10000)
+
+	mux.HandleFunc("/api/v1/checking/checks", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, http.MethodPost)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Empty(t, r.Header.Get("Content-Encoding"))
+
+		var opts SubmitCheckOptions
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&opts))
+		assert.Equal(t, content, opts.Content)
+		assert.Equal(t, "MARKDOWN", opts.CheckOptions.ContentFormat)
+		assert.Equal(t, "docs/guide.md", opts.Document.Reference)
+
+		mustWriteHTTPResponse(t, w, "submit_check.json")
+	})
+
+	opts := &SubmitCheckOptions{
+		CheckOptions: &CheckOptions{ContentFormat: "MARKDOWN"},
+		Document:     &Document{Reference: "docs/guide.md"},
+	}
+	check, links, err := client.Checking.SubmitCheckFromReader(strings.NewReader(content), opts, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "052929ee-be0c-46a7-87ce-eebd308fef6e", check.ID)
+	assert.Contains(t, links, "result")
+}
+
+func TestSubmitCheckFromReaderWithGzipAndBase64(t *testing.T) {
+	mux, server, client := setup(t)
+	defer teardown(server)
+
+	content := []byte("PK\x03\x04\x00\xff binary")
+
+	mux.HandleFunc("/api/v1/checking/checks", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+
+		body, err := gzip.NewReader(r.Body)
+		assert.NoError(t, err)
+
+		var opts SubmitCheckOptions
+		assert.NoError(t, json.NewDecoder(body).Decode(&opts))
+		assert.Equal(t, "base64", opts.ContentEncoding)
+
+		data, err := opts.BinaryContent()
+		assert.NoError(t, err)
+		assert.Equal(t, content, data)
+
+		mustWriteHTTPResponse(t, w, "submit_check.json")
+	})
+
+	opts := &SubmitCheckOptions{ContentEncoding: ContentEncodingBase64}
+	_, _, err := client.Checking.SubmitCheckFromReader(bytes.NewReader(content), opts, &StreamOptions{Gzip: true})
+	assert.NoError(t, err)
+}
+
+type failingReader struct{}
+
+func (failingReader) Read(p []byte) (int, error) {
+	return 0, io.ErrUnexpectedEOF
+}
+
+func TestSubmitCheckFromReaderWithReadError(t *testing.T) {
+	mux, server, client := setup(t)
+	defer teardown(server)
+
+	mux.HandleFunc("/api/v1/checking/checks", func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		mustWriteHTTPResponse(t, w, "submit_check.json")
+	})
+
+	_, _, err := client.Checking.SubmitCheckFromReader(failingReader{}, &SubmitCheckOptions{}, nil)
+	assert.ErrorContains(t, err, "Error reading content")
+}
@@ -0,0 +1,223 @@
+package acrolinx
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	DirectiveDisable         = "disable"
+	DirectiveEnable          = "enable"
+	DirectiveDisableLine     = "disable-line"
+	DirectiveDisableNextLine = "disable-next-line"
+)
+
+// Directive is a comment in the content controlling which issues are
+// reported, like <!-- acrolinx-disable-next-line simpler_word -->.
+type Directive struct {
+	Kind string
+	// Rules lists the goal IDs and internal names of the issues the
+	// directive applies to. It is empty for directives applying to all
+	// issues.
+	Rules []string
+	// Line is the line of the directive, starting at one.
+	Line int
+	// Begin and End are the UTF-16 offsets of the comment.
+	Begin int
+	End   int
+}
+
+// rulesMatch reports whether issue is one of rules, or rules is empty.
+func rulesMatch(rules []string, issue *Issue) bool {
+	if len(rules) == 0 {
+		return true
+	}
+
+	for _, rule := range rules {
+		if strings.EqualFold(rule, issue.GoalID) || strings.EqualFold(rule, issue.InternalName) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// suppression is a range of the content in which a directive disables
+// issues. The range is given in UTF-16 offsets.
+type suppression struct {
+	directive *Directive
+	rules     []string
+	begin     int
+	end       int
+}
+
+// Suppressions holds the directives of a document.
+type Suppressions struct {
+	Directives []*Directive
+
+	suppressions []*suppression
+	// unmatched lists enable directives that closed no block.
+	unmatched []*Directive
+}
+
+type SuppressedIssue struct {
+	Issue     *Issue
+	Directive *Directive
+}
+
+type SuppressionResult struct {
+	// Issues lists the issues not suppressed by any directive.
+	Issues     []*Issue
+	Suppressed []*SuppressedIssue
+	// Unused lists the directives that didn't suppress any issue, and
+	// enable directives without a preceding disable directive.
+	Unused []*Directive
+}
+
+var directivePattern = regexp.MustCompile(`(?s)<!--\s*acrolinx-(disable-next-line|disable-line|disable|enable)\b(.*?)-->`)
+
+// ParseSuppressions finds the directives in HTML, XML and Markdown
+// comments of content:
+//
+//	<!-- acrolinx-disable-next-line [rules] -->
+//	<!-- acrolinx-disable-line [rules] -->
+//	<!-- acrolinx-disable [rules] -->
+//	<!-- acrolinx-enable [rules] -->
+//
+// Rules are goal IDs or internal names of issues, separated by commas
+// or spaces. Directives without rules apply to all issues. A disable
+// directive applies until an enable directive for the same rules, or
+// an enable directive without rules.
+func ParseSuppressions(content string) *Suppressions {
+	s := &Suppressions{}
+	x := NewPositionIndex(content)
+
+	// Open disable blocks by rule, "" for blocks without rules.
+	open := make(map[string]*suppression)
+	closeBlock := func(key string, end int) bool {
+		block, ok := open[key]
+		if ok {
+			block.end = end
+			delete(open, key)
+		}
+		return ok
+	}
+
+	for _, m := range directivePattern.FindAllStringSubmatchIndex(content, -1) {
+		begin, end := x.FromByte(m[0]), x.FromByte(m[1])
+		d := &Directive{
+			Kind:  content[m[2]:m[3]],
+			Rules: strings.FieldsFunc(content[m[4]:m[5]], func(r rune) bool { return r == ',' || r == ' ' || r == '\t' || r == '\n' || r == '\r' }),
+			Line:  begin.Line,
+			Begin: begin.UTF16,
+			End:   end.UTF16,
+		}
+		s.Directives = append(s.Directives, d)
+
+		switch d.Kind {
+		case DirectiveDisableLine:
+			lineBegin, lineEnd := x.lineRange(begin.Line)
+			s.suppressions = append(s.suppressions, &suppression{d, d.Rules, lineBegin, lineEnd})
+		case DirectiveDisableNextLine:
+			lineBegin, lineEnd := x.lineRange(end.Line + 1)
+			s.suppressions = append(s.suppressions, &suppression{d, d.Rules, lineBegin, lineEnd})
+		case DirectiveDisable:
+			keys := d.Rules
+			if len(keys) == 0 {
+				keys = []string{""}
+			}
+			for _, key := range keys {
+				closeBlock(key, begin.UTF16)
+				block := &suppression{d, nil, end.UTF16, x.Len()}
+				if key != "" {
+					block.rules = []string{key}
+				}
+				open[key] = block
+				s.suppressions = append(s.suppressions, block)
+			}
+		case DirectiveEnable:
+			closed := false
+			if len(d.Rules) == 0 {
+				for key := range open {
+					closed = closeBlock(key, begin.UTF16) || closed
+				}
+			}
+			for _, key := range d.Rules {
+				closed = closeBlock(key, begin.UTF16) || closed
+			}
+			if !closed {
+				s.unmatched = append(s.unmatched, d)
+			}
+		}
+	}
+
+	return s
+}
+
+// lineRange returns the UTF-16 offsets of the beginning and end of a
+// line, including its line break.
+func (x *PositionIndex) lineRange(line int) (int, int) {
+	if line > len(x.lines) {
+		return x.Len(), x.Len()
+	}
+
+	begin := x.lines[line-1].utf16
+	if line == len(x.lines) {
+		return begin, x.Len()
+	}
+
+	return begin, x.lines[line].utf16
+}
+
+// Filter removes the issues disabled by a directive. An issue is
+// disabled if its first match starts in the range of a directive for
+// its goal or internal name. Issues without matches are never disabled.
+func (s *Suppressions) Filter(issues []*Issue) *SuppressionResult {
+	result := &SuppressionResult{}
+	used := make(map[*Directive]bool)
+
+	for _, issue := range issues {
+		if sup := s.find(issue); sup != nil {
+			used[sup.directive] = true
+			result.Suppressed = append(result.Suppressed, &SuppressedIssue{issue, sup.directive})
+			continue
+		}
+		result.Issues = append(result.Issues, issue)
+	}
+
+	for _, d := range s.Directives {
+		if d.Kind != DirectiveEnable && !used[d] {
+			result.Unused = append(result.Unused, d)
+		}
+	}
+	result.Unused = append(result.Unused, s.unmatched...)
+	sort.SliceStable(result.Unused, func(i, j int) bool {
+		return result.Unused[i].Begin < result.Unused[j].Begin
+	})
+
+	return result
+}
+
+func (s *Suppressions) find(issue *Issue) *suppression {
+	if issue.PositionalInformation == nil || len(issue.PositionalInformation.Matches) == 0 {
+		return nil
+	}
+
+	begin := issue.PositionalInformation.Matches[0].OriginalBegin
+	for _, m := range issue.PositionalInformation.Matches[1:] {
+		begin = min(begin, m.OriginalBegin)
+	}
+
+	for _, sup := range s.suppressions {
+		if begin < sup.begin || begin >= sup.end {
+			continue
+		}
+
+		if rulesMatch(sup.rules, issue) {
+			return sup
+		}
+	}
+
+	return nil
+}
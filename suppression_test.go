@@ -0,0 +1,96 @@
+package acrolinx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const suppressionTestContent = `# Guide
+
+<!-- acrolinx-disable-next-line simpler_word -->
+You can utilise the tool.
+You can utilise it twice. <!-- acrolinx-disable-line CLARITY -->
+<!-- acrolinx-disable -->
+Utilise everything, it is not hard.
+<!-- acrolinx-enable -->
+<!-- acrolinx-disable contraction, spelling -->
+It is not done.
+<!-- acrolinx-enable contraction -->
+It is not over.
+<!-- acrolinx-enable terminology -->
+<!-- acrolinx-disable-next-line -->
+Nothing to see here.
+`
+
+func suppressionTestIssue(name string, goal string, part string, occurrence int) *Issue {
+	i := -1
+	for n := 0; n <= occurrence; n++ {
+		i += 1 + strings.Index(suppressionTestContent[i+1:], part)
+	}
+
+	return &Issue{
+		GoalID:       goal,
+		InternalName: name,
+		PositionalInformation: &PositionalInformation{
+			Matches: []*Match{reportMatchAt(suppressionTestContent, i, part)},
+		},
+	}
+}
+
+func TestParseSuppressions(t *testing.T) {
+	s := ParseSuppressions(suppressionTestContent)
+
+	var kinds []string
+	for _, d := range s.Directives {
+		kinds = append(kinds, d.Kind)
+	}
+	assert.Equal(t, []string{
+		DirectiveDisableNextLine, DirectiveDisableLine, DirectiveDisable, DirectiveEnable,
+		DirectiveDisable, DirectiveEnable, DirectiveEnable, DirectiveDisableNextLine,
+	}, kinds)
+
+	assert.Equal(t, []string{"simpler_word"}, s.Directives[0].Rules)
+	assert.Equal(t, 3, s.Directives[0].Line)
+	assert.Equal(t, []string{"contraction", "spelling"}, s.Directives[4].Rules)
+	assert.Empty(t, s.Directives[2].Rules)
+	assert.Equal(t, "<!-- acrolinx-disable -->", suppressionTestContent[s.Directives[2].Begin:s.Directives[2].End])
+}
+
+func TestSuppressionsFilter(t *testing.T) {
+	issues := []*Issue{
+		suppressionTestIssue("simpler_word", "CLARITY", "utilise", 0),
+		suppressionTestIssue("simpler_word", "CLARITY", "utilise", 1),
+		suppressionTestIssue("simpler_word", "CLARITY", "Utilise", 0),
+		suppressionTestIssue("contraction", "CONSISTENCY", "is not", 0),
+		suppressionTestIssue("contraction", "CONSISTENCY", "is not", 1),
+		suppressionTestIssue("contraction", "CONSISTENCY", "is not", 2),
+		{GoalID: "CLARITY", InternalName: "document_too_long"},
+	}
+
+	result := ParseSuppressions(suppressionTestContent).Filter(issues)
+
+	assert.Equal(t, []*Issue{issues[5], issues[6]}, result.Issues)
+
+	var suppressed []int
+	for _, s := range result.Suppressed {
+		suppressed = append(suppressed, s.Directive.Line)
+	}
+	assert.Equal(t, []int{3, 5, 6, 6, 9}, suppressed)
+
+	var unused []string
+	for _, d := range result.Unused {
+		unused = append(unused, d.Kind+" "+strings.Join(d.Rules, ","))
+	}
+	assert.Equal(t, []string{"enable terminology", "disable-next-line "}, unused)
+}
+
+func TestSuppressionsWithoutDirectives(t *testing.T) {
+	issues := reportTestDocuments()[0].Result.Issues
+	result := ParseSuppressions(reportTestContent).Filter(issues)
+
+	assert.Equal(t, issues, result.Issues)
+	assert.Empty(t, result.Suppressed)
+	assert.Empty(t, result.Unused)
+}
@@ -0,0 +1,241 @@
+package acrolinx
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type TextOptions struct {
+	// Color enables ANSI colors in the output. Use ColorSupported to
+	// find out whether a terminal supports them.
+	Color bool
+}
+
+// ColorSupported reports whether f is a terminal that supports colors.
+// Colors are turned off by setting NO_COLOR or TERM=dumb.
+func ColorSupported(f *os.File) bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// WriteText writes a human-readable report of docs to w. Every issue is
+// shown with its location in the form file:line:column, its guidance,
+// the affected line and its suggestions. A summary of the quality
+// scores follows the issues.
+func WriteText(w io.Writer, docs []*CheckedDocument, opts *TextOptions) error {
+	if opts == nil {
+		opts = &TextOptions{}
+	}
+
+	tw := &textWriter{opts: opts}
+	docs = sortedDocuments(docs)
+
+	for _, doc := range docs {
+		tw.writeIssues(doc)
+	}
+	tw.writeSummary(docs)
+
+	if _, err := io.WriteString(w, tw.b.String()); err != nil {
+		return fmt.Errorf("Error writing text report: %w", err)
+	}
+
+	return nil
+}
+
+type textWriter struct {
+	b    strings.Builder
+	opts *TextOptions
+}
+
+func (tw *textWriter) writeIssues(doc *CheckedDocument) {
+	path := doc.slashPath()
+
+	for _, issue := range doc.locatedIssues() {
+		goalName, color := issue.GoalID, ""
+		if g := doc.goal(issue.Issue); g != nil {
+			goalName, color = g.DisplayName, g.Color
+		}
+
+		tw.printf("%s: %s: %s [%s/%s]\n",
+			tw.style(issueLocation(path, issue), ansiBold),
+			doc.severity(issue.Issue),
+			issueTitle(issue.Issue),
+			tw.style(goalName, ansiColor(color)),
+			issueRuleID(issue.Issue),
+		)
+
+		if issue.Located {
+			tw.writeSnippet(doc.Content, issue.Range, color)
+		}
+
+		if guidance := htmlToText(issue.GuidanceHTML); guidance != "" {
+			for _, line := range strings.Split(guidance, "\n") {
+				if line == "" {
+					tw.printf("\n")
+					continue
+				}
+				tw.printf("    %s\n", line)
+			}
+		}
+
+		if len(issue.Suggestions) > 0 {
+			surfaces := make([]string, len(issue.Suggestions))
+			for i, s := range issue.Suggestions {
+				surfaces[i] = s.Surface
+			}
+			tw.printf("    suggestions: %s\n", strings.Join(surfaces, ", "))
+		}
+
+		tw.printf("\n")
+	}
+}
+
+// writeSnippet prints the first line of r and underlines the part
+// covered by r.
+func (tw *textWriter) writeSnippet(content string, r Range, color string) {
+	lineStart := r.Start.Byte - (r.Start.ByteColumn - 1)
+	lineEnd := strings.IndexAny(content[r.Start.Byte:], "\r\n")
+	if lineEnd < 0 {
+		lineEnd = len(content)
+	} else {
+		lineEnd += r.Start.Byte
+	}
+
+	line := content[lineStart:lineEnd]
+	prefix := content[lineStart:r.Start.Byte]
+	marked := content[r.Start.Byte:min(r.End.Byte, lineEnd)]
+
+	number := strconv.Itoa(r.Start.Line)
+	gutter := strings.Repeat(" ", len(number))
+
+	tw.printf("  %s |\n", gutter)
+	tw.printf("  %s | %s\n", number, strings.ReplaceAll(line, "\t", "    "))
+	tw.printf("  %s | %s%s\n", gutter, textIndent(prefix), tw.style(strings.Repeat("^", max(textWidth(marked), 1)), ansiColor(color)))
+}
+
+func (tw *textWriter) writeSummary(docs []*CheckedDocument) {
+	total := 0
+
+	for _, doc := range docs {
+		issues := len(doc.locatedIssues())
+		total += issues
+
+		tw.printf("%s: %s\n", tw.style(doc.slashPath(), ansiBold), textIssueCount(issues))
+		if doc.Result == nil || doc.Result.Quality == nil {
+			continue
+		}
+
+		quality := doc.Result.Quality
+		tw.printf("  Score: %d (%s)\n", quality.Score, quality.Status)
+
+		for _, score := range quality.ScoresByGoal {
+			name, color := score.ID, ""
+			for _, g := range doc.Result.Goals {
+				if g.ID == score.ID {
+					name, color = g.DisplayName, g.Color
+				}
+			}
+			tw.printf("  %s: %d\n", tw.style(name, ansiColor(color)), score.Score)
+		}
+	}
+
+	tw.printf("\n%d documents, %s\n", len(docs), textIssueCount(total))
+}
+
+func (tw *textWriter) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&tw.b, format, args...)
+}
+
+// style wraps s in the escape sequence code if colors are enabled.
+func (tw *textWriter) style(s string, code string) string {
+	if !tw.opts.Color || code == "" {
+		return s
+	}
+
+	return code + s + ansiReset
+}
+
+const (
+	ansiBold  = "\x1b[1m"
+	ansiReset = "\x1b[0m"
+)
+
+// ansiColor converts a color like #ec407a into a 24-bit ANSI foreground
+// color. It returns an empty string for invalid colors.
+func ansiColor(hex string) string {
+	hex = strings.TrimPrefix(hex, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+
+	if len(hex) != 6 {
+		return ""
+	}
+
+	rgb, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return ""
+	}
+
+	return fmt.Sprintf("\x1b[38;2;%d;%d;%dm", rgb>>16, rgb>>8&0xff, rgb&0xff)
+}
+
+func textIssueCount(n int) string {
+	switch n {
+	case 0:
+		return "no issues"
+	case 1:
+		return "1 issue"
+	}
+
+	return fmt.Sprintf("%d issues", n)
+}
+
+// textIndent returns whitespace as wide as s in a terminal.
+func textIndent(s string) string {
+	return strings.Repeat(" ", textWidth(s))
+}
+
+// textWidth returns the number of terminal cells needed to display s.
+// Tabs are expanded to four spaces.
+func textWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		switch {
+		case r == '\t':
+			width += 4
+		case isWideRune(r):
+			width += 2
+		case r >= ' ':
+			width++
+		}
+	}
+
+	return width
+}
+
+// isWideRune reports whether r takes two cells in a terminal, which is
+// the case for most CJK characters and emoji.
+func isWideRune(r rune) bool {
+	return r >= 0x1100 && (r <= 0x115f ||
+		r >= 0x2e80 && r <= 0xa4cf && r != 0x303f ||
+		r >= 0xac00 && r <= 0xd7a3 ||
+		r >= 0xf900 && r <= 0xfaff ||
+		r >= 0xfe30 && r <= 0xfe4f ||
+		r >= 0xff00 && r <= 0xff60 ||
+		r >= 0xffe0 && r <= 0xffe6 ||
+		r >= 0x1f300 && r <= 0x1f64f ||
+		r >= 0x1f900 && r <= 0x1f9ff ||
+		r >= 0x20000 && r <= 0x3fffd)
+}
@@ -0,0 +1,66 @@
+package acrolinx
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteText(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, WriteText(&buf, reportTestDocuments(), nil))
+	assertGolden(t, "report.txt", buf.String())
+	assert.NotContains(t, buf.String(), "\x1b[")
+}
+
+func TestWriteTextColor(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, WriteText(&buf, reportTestDocuments(), &TextOptions{Color: true}))
+
+	assert.Contains(t, buf.String(), "[\x1b[38;2;236;64;122mClarity\x1b[0m/simpler_word]")
+	assert.Contains(t, buf.String(), "\x1b[1mdocs/guide.md:3:23\x1b[0m")
+}
+
+func TestWriteTextUnderline(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, WriteText(&buf, reportTestDocuments(), nil))
+
+	// Wide characters take two cells, so the carets are indented by 10.
+	lines := strings.Split(buf.String(), "\n")
+	for i, line := range lines {
+		if strings.HasSuffix(line, "is not supported.") {
+			assert.Equal(t, "    | "+strings.Repeat(" ", 10)+"^^^^^^", lines[i+1])
+			return
+		}
+	}
+	t.Fatal("snippet not found")
+}
+
+func TestAnsiColor(t *testing.T) {
+	assert.Equal(t, "\x1b[38;2;236;64;122m", ansiColor("#ec407a"))
+	assert.Equal(t, "\x1b[38;2;255;0;0m", ansiColor("#f00"))
+	assert.Equal(t, "", ansiColor("red"))
+	assert.Equal(t, "", ansiColor(""))
+}
+
+func TestColorSupported(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	assert.NoError(t, err)
+	defer f.Close()
+
+	t.Setenv("TERM", "xterm-256color")
+	assert.False(t, ColorSupported(f))
+
+	t.Setenv("NO_COLOR", "")
+	assert.False(t, ColorSupported(os.Stdout))
+}
+
+func TestTextWidth(t *testing.T) {
+	assert.Equal(t, 3, textWidth("abc"))
+	assert.Equal(t, 10, textWidth("日本語 😀 "))
+	assert.Equal(t, 5, textWidth("\ta"))
+}
@@ -0,0 +1,184 @@
+package acrolinx
+
+import (
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// FieldError describes a problem with a single field of a request.
+// Field is the JSON path of the field, e.g. "checkOptions.checkType",
+// or empty if the problem concerns the whole request.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+
+	return e.Field + ": " + e.Message
+}
+
+// ValidationError collects all problems found in a request.
+type ValidationError struct {
+	Errors []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+
+	return "Invalid check options: " + strings.Join(msgs, "; ")
+}
+
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+
+	return errs
+}
+
+type validator struct {
+	errors []*FieldError
+}
+
+func (v *validator) addf(field string, format string, args ...interface{}) {
+	v.errors = append(v.errors, &FieldError{field, fmt.Sprintf(format, args...)})
+}
+
+// ValidateSubmitCheckOptions checks opts against the capabilities of
+// the platform before submitting them. It returns a *ValidationError
+// listing every problem found, or nil.
+func ValidateSubmitCheckOptions(opts *SubmitCheckOptions, caps *Capabilities) error {
+	var v validator
+
+	if opts == nil {
+		v.addf("", "missing check options")
+	}
+	if caps == nil {
+		v.addf("", "missing capabilities")
+	}
+	if len(v.errors) > 0 {
+		return &ValidationError{v.errors}
+	}
+
+	o := opts.CheckOptions
+	if o == nil {
+		o = &CheckOptions{}
+	}
+
+	switch {
+	case o.GuidanceProfileID == "" && caps.DefaultGuidanceProfileID == "":
+		v.addf("checkOptions.guidanceProfileId", "no guidance profile given and the platform has no default")
+	case o.GuidanceProfileID != "" && caps.GuidanceProfileByID(o.GuidanceProfileID) == nil:
+		v.addf("checkOptions.guidanceProfileId", "unknown guidance profile %q", o.GuidanceProfileID)
+	}
+
+	if o.ContentFormat != "" && o.ContentFormat != ContentFormatAuto && !caps.SupportsContentFormat(o.ContentFormat) {
+		ids := make([]string, len(caps.ContentFormats))
+		for i, f := range caps.ContentFormats {
+			ids[i] = f.ID
+		}
+		v.addf("checkOptions.contentFormat", "unsupported content format %q%s", o.ContentFormat, didYouMean(o.ContentFormat, ids))
+	}
+
+	if o.CheckType != "" && !caps.SupportsCheckType(o.CheckType) {
+		v.addf("checkOptions.checkType", "unsupported check type %q%s", o.CheckType, didYouMean(o.CheckType, caps.CheckTypes))
+	}
+
+	for i, reportType := range o.ReportTypes {
+		if !caps.SupportsReportType(reportType) {
+			v.addf(fmt.Sprintf("checkOptions.reportTypes[%d]", i), "unsupported report type %q%s", reportType, didYouMean(reportType, caps.ReportTypes))
+		}
+	}
+
+	switch opts.ContentEncoding {
+	case "", ContentEncodingNone:
+		v.validateRanges(o.PartialCheckRanges, utf16Length(opts.Content))
+	case ContentEncodingBase64:
+		if !caps.SupportsContentEncoding(opts.ContentEncoding) {
+			v.addf("contentEncoding", "unsupported content encoding %q", opts.ContentEncoding)
+		}
+		if _, err := base64.StdEncoding.DecodeString(opts.Content); err != nil {
+			v.addf("content", "invalid base64 content: %v", err)
+		}
+	default:
+		if !caps.SupportsContentEncoding(opts.ContentEncoding) {
+			v.addf("contentEncoding", "unsupported content encoding %q", opts.ContentEncoding)
+		}
+	}
+
+	if len(v.errors) > 0 {
+		return &ValidationError{v.errors}
+	}
+
+	return nil
+}
+
+func (v *validator) validateRanges(ranges []*PartialCheckRange, contentLength int) {
+	type indexedRange struct {
+		index int
+		r     *PartialCheckRange
+	}
+
+	var valid []indexedRange
+	for i, r := range ranges {
+		field := fmt.Sprintf("checkOptions.partialCheckRanges[%d]", i)
+		switch {
+		case r == nil:
+			v.addf(field, "missing range")
+		case r.Begin < 0 || r.End < r.Begin:
+			v.addf(field, "invalid range %d-%d", r.Begin, r.End)
+		case r.End > contentLength:
+			v.addf(field, "range %d-%d exceeds content length %d", r.Begin, r.End, contentLength)
+		default:
+			valid = append(valid, indexedRange{i, r})
+		}
+	}
+
+	slices.SortStableFunc(valid, func(a, b indexedRange) int {
+		return a.r.Begin - b.r.Begin
+	})
+
+	// Compare every range with the one ending last before it, which may
+	// contain several later ranges.
+	var last indexedRange
+	for i, cur := range valid {
+		if i > 0 && cur.r.Begin < last.r.End {
+			v.addf(fmt.Sprintf("checkOptions.partialCheckRanges[%d]", cur.index),
+				"range %d-%d overlaps range %d", cur.r.Begin, cur.r.End, last.index)
+		}
+		if i == 0 || cur.r.End > last.r.End {
+			last = cur
+		}
+	}
+}
+
+func didYouMean(value string, candidates []string) string {
+	for _, c := range candidates {
+		if strings.EqualFold(value, c) {
+			return fmt.Sprintf(" (did you mean %q?)", c)
+		}
+	}
+
+	return ""
+}
+
+// utf16Length returns the length of s in UTF-16 code units, which is
+// the unit of all offsets exchanged with the platform.
+func utf16Length(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16Units(r)
+	}
+
+	return n
+}
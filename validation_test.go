@@ -0,0 +1,127 @@
+package acrolinx
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSubmitCheckOptions(t *testing.T) {
+	opts := &SubmitCheckOptions{
+		Content: "Some text",
+		CheckOptions: &CheckOptions{
+			GuidanceProfileID: "en-1",
+			ContentFormat:     "MARKDOWN",
+			CheckType:         "batch",
+			ReportTypes:       []string{"scorecard"},
+			PartialCheckRanges: []*PartialCheckRange{
+				{5, 9},
+				{0, 4},
+			},
+		},
+	}
+
+	assert.NoError(t, ValidateSubmitCheckOptions(opts, testCapabilities()))
+}
+
+func TestValidateSubmitCheckOptionsWithDefaultProfile(t *testing.T) {
+	opts := &SubmitCheckOptions{Content: "Some text"}
+
+	assert.NoError(t, ValidateSubmitCheckOptions(opts, testCapabilities()))
+
+	err := ValidateSubmitCheckOptions(opts, &Capabilities{})
+	assert.EqualError(t, err, "Invalid check options: checkOptions.guidanceProfileId: no guidance profile given and the platform has no default")
+}
+
+func TestValidateSubmitCheckOptionsWithErrors(t *testing.T) {
+	opts := &SubmitCheckOptions{
+		Content: "Some 😀 text",
+		CheckOptions: &CheckOptions{
+			GuidanceProfileID: "fr-1",
+			ContentFormat:     "markdown",
+			CheckType:         "quick",
+			ReportTypes:       []string{"scorecard", "termHarvesting"},
+			PartialCheckRanges: []*PartialCheckRange{
+				{0, 6},
+				{4, 8},
+				{9, 13},
+				{8, 3},
+				nil,
+			},
+		},
+	}
+
+	err := ValidateSubmitCheckOptions(opts, testCapabilities())
+
+	var validationErr *ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, []*FieldError{
+		{"checkOptions.guidanceProfileId", `unknown guidance profile "fr-1"`},
+		{"checkOptions.contentFormat", `unsupported content format "markdown" (did you mean "MARKDOWN"?)`},
+		{"checkOptions.checkType", `unsupported check type "quick"`},
+		{"checkOptions.reportTypes[1]", `unsupported report type "termHarvesting"`},
+		{"checkOptions.partialCheckRanges[2]", "range 9-13 exceeds content length 12"},
+		{"checkOptions.partialCheckRanges[3]", "invalid range 8-3"},
+		{"checkOptions.partialCheckRanges[4]", "missing range"},
+		{"checkOptions.partialCheckRanges[1]", "range 4-8 overlaps range 0"},
+	}, validationErr.Errors)
+
+	var fieldErr *FieldError
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "checkOptions.guidanceProfileId", fieldErr.Field)
+}
+
+func TestValidateNestedPartialCheckRanges(t *testing.T) {
+	opts := &SubmitCheckOptions{
+		Content: strings.Repeat("x", 100),
+		CheckOptions: &CheckOptions{
+			PartialCheckRanges: []*PartialCheckRange{{30, 40}, {0, 100}, {10, 20}},
+		},
+	}
+
+	var validationErr *ValidationError
+	assert.True(t, errors.As(ValidateSubmitCheckOptions(opts, testCapabilities()), &validationErr))
+	assert.Equal(t, []*FieldError{
+		{"checkOptions.partialCheckRanges[2]", "range 10-20 overlaps range 1"},
+		{"checkOptions.partialCheckRanges[0]", "range 30-40 overlaps range 1"},
+	}, validationErr.Errors)
+}
+
+func TestValidateNilSubmitCheckOptions(t *testing.T) {
+	assert.Error(t, ValidateSubmitCheckOptions(nil, testCapabilities()))
+}
+
+func TestValidateSubmitCheckOptionsWithoutCapabilities(t *testing.T) {
+	err := ValidateSubmitCheckOptions(&SubmitCheckOptions{Content: "text"}, nil)
+
+	var validationErr *ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, []*FieldError{{"", "missing capabilities"}}, validationErr.Errors)
+}
+
+func TestUTF16Length(t *testing.T) {
+	assert.Equal(t, 0, utf16Length(""))
+	assert.Equal(t, 4, utf16Length("text"))
+	assert.Equal(t, 2, utf16Length("日本"))
+	assert.Equal(t, 2, utf16Length("😀"))
+}
+
+func TestValidateSubmitCheckOptionsWithAutoFormat(t *testing.T) {
+	opts := &SubmitCheckOptions{CheckOptions: &CheckOptions{ContentFormat: ContentFormatAuto}}
+
+	assert.NoError(t, ValidateSubmitCheckOptions(opts, testCapabilities()))
+}
+
+func TestValidateSubmitCheckOptionsWithEncoding(t *testing.T) {
+	opts := &SubmitCheckOptions{Content: "UEsDBA==", ContentEncoding: ContentEncodingBase64}
+	assert.NoError(t, ValidateSubmitCheckOptions(opts, testCapabilities()))
+
+	opts = &SubmitCheckOptions{Content: "not base64!", ContentEncoding: ContentEncodingBase64}
+	assert.ErrorContains(t, ValidateSubmitCheckOptions(opts, testCapabilities()), "content: invalid base64 content")
+
+	opts = &SubmitCheckOptions{Content: "text", ContentEncoding: "gzip"}
+	assert.EqualError(t, ValidateSubmitCheckOptions(opts, testCapabilities()),
+		`Invalid check options: contentEncoding: unsupported content encoding "gzip"`)
+}
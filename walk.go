@@ -0,0 +1,382 @@
+package acrolinx
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+)
+
+const defaultWalkConcurrency = 4
+
+// WalkOptions select the files of a tree to check and how to check
+// them. Patterns are slash-separated globs relative to the root of the
+// tree, where "**" matches any number of directories. Patterns without
+// a slash match file names in any directory; a leading slash anchors
+// them to the root.
+type WalkOptions struct {
+	// Include lists the patterns of files to check. Defaults to all
+	// files.
+	Include []string
+	// Exclude lists the patterns of files and directories to skip.
+	Exclude []string
+	// NoGitignore disables skipping the files ignored by .gitignore
+	// files in the tree.
+	NoGitignore bool
+
+	// CheckOptions are the options every file is checked with.
+	CheckOptions *CheckOptions
+	// Formats resolves the content format of files whose CheckOptions
+	// don't set one. Defaults to a resolver without capabilities.
+	Formats *ContentFormatResolver
+	// Profiles picks the guidance profile of files. The first rule
+	// matching a file wins; other files use CheckOptions.
+	Profiles []*ProfileRule
+	// ReferencePrefix is prepended to the path of every file to form
+	// its Document.Reference, for example the URL of a repository.
+	ReferencePrefix string
+
+	// Concurrency is the maximum number of files checked at the same
+	// time. Defaults to 4.
+	Concurrency int
+}
+
+// ProfileRule selects the guidance profile for files matching Pattern.
+type ProfileRule struct {
+	Pattern           string
+	GuidanceProfileID string
+}
+
+// FileResult is the outcome of checking a file of a tree. Document.Path
+// is the slash-separated path of the file in the tree; Err is set if
+// the file could not be read or checked.
+type FileResult struct {
+	Document *CheckedDocument
+	Err      error
+}
+
+// FindFiles returns the paths of the files in fsys selected by opts, in
+// lexical order. It returns an error if a pattern of opts is invalid.
+func FindFiles(fsys fs.FS, opts *WalkOptions) ([]string, error) {
+	if opts == nil {
+		opts = &WalkOptions{}
+	}
+
+	for _, rule := range opts.Profiles {
+		if _, err := compileGlobs([]string{rule.Pattern}); err != nil {
+			return nil, err
+		}
+	}
+
+	include, err := compileGlobs(opts.Include)
+	if err != nil {
+		return nil, err
+	}
+
+	exclude, err := compileGlobs(opts.Exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	var ignore gitignore
+	var files []string
+	err = fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if p != "." && (d.Name() == ".git" || exclude.match(p) || ignore.match(p, d.IsDir())) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if d.IsDir() {
+			if !opts.NoGitignore {
+				return ignore.load(fsys, p)
+			}
+			return nil
+		}
+
+		if d.Type().IsRegular() && (len(include) == 0 || include.match(p)) {
+			files = append(files, p)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error walking files: %w", err)
+	}
+
+	return files, nil
+}
+
+// submitOptions returns the options to check the file at p with. It
+// returns ErrBinaryContent for binary files of a text format.
+func (o *WalkOptions) submitOptions(p string, content []byte) (*SubmitCheckOptions, error) {
+	checkOpts := &CheckOptions{}
+	if o.CheckOptions != nil {
+		*checkOpts = *o.CheckOptions
+	}
+
+	for _, rule := range o.Profiles {
+		if compileGlob(rule.Pattern).match(p) {
+			checkOpts.GuidanceProfileID = rule.GuidanceProfileID
+			break
+		}
+	}
+
+	opts := &SubmitCheckOptions{CheckOptions: checkOpts}
+
+	if checkOpts.ContentFormat == "" {
+		formats := o.Formats
+		if formats == nil {
+			formats, _ = NewContentFormatResolver(nil)
+		}
+		formats.Apply(opts, p, content)
+	}
+
+	opts.Document = &Document{Reference: o.ReferencePrefix + p}
+
+	if err := opts.SetFileContent(content); err != nil {
+		return nil, err
+	}
+
+	return opts, nil
+}
+
+// CheckFS checks the files of fsys selected by opts with checker. The
+// results are sent on the returned channel as the checks complete; it
+// is closed after all files have been checked or ctx is done. Binary
+// files of text formats, like images, are skipped. An error is returned
+// if the files can't be listed.
+func CheckFS(ctx context.Context, checker Checker, fsys fs.FS, opts *WalkOptions) (<-chan *FileResult, error) {
+	if opts == nil {
+		opts = &WalkOptions{}
+	}
+
+	files, err := FindFiles(fsys, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	concurrency := defaultWalkConcurrency
+	if opts.Concurrency > 0 {
+		concurrency = opts.Concurrency
+	}
+
+	paths := make(chan string)
+	results := make(chan *FileResult)
+
+	go func() {
+		defer close(paths)
+		for _, p := range files {
+			select {
+			case paths <- p:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < min(concurrency, len(files)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range paths {
+				result := checkFile(ctx, checker, fsys, opts, p)
+				if result == nil {
+					continue
+				}
+				select {
+				case results <- result:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results, nil
+}
+
+// checkFile checks the file at p. It returns nil if the file is
+// skipped.
+func checkFile(ctx context.Context, checker Checker, fsys fs.FS, opts *WalkOptions, p string) *FileResult {
+	doc := &CheckedDocument{Path: p}
+
+	content, err := fs.ReadFile(fsys, p)
+	if err != nil {
+		return &FileResult{Document: doc, Err: fmt.Errorf("Error reading %s: %w", p, err)}
+	}
+	doc.Content = string(content)
+
+	submitOpts, err := opts.submitOptions(p, content)
+	if errors.Is(err, ErrBinaryContent) {
+		return nil
+	}
+	if submitOpts.ContentEncoding == ContentEncodingBase64 {
+		doc.Content = ""
+	}
+
+	result, err := checker.Check(ctx, submitOpts)
+	if err != nil {
+		return &FileResult{Document: doc, Err: fmt.Errorf("Error checking %s: %w", p, err)}
+	}
+	doc.Result = result
+
+	return &FileResult{Document: doc}
+}
+
+// glob is a pattern split into path segments.
+type glob []string
+
+func compileGlob(pattern string) glob {
+	pattern = strings.TrimSuffix(pattern, "/")
+	if !strings.Contains(pattern, "/") {
+		pattern = "**/" + pattern
+	}
+
+	return strings.Split(strings.TrimPrefix(pattern, "/"), "/")
+}
+
+func (g glob) match(p string) bool {
+	return matchSegments(g, strings.Split(p, "/"))
+}
+
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(segments) == 0 {
+		return false
+	}
+
+	ok, _ := path.Match(pattern[0], segments[0])
+	return ok && matchSegments(pattern[1:], segments[1:])
+}
+
+type globs []glob
+
+func compileGlobs(patterns []string) (globs, error) {
+	var gs globs
+	for _, pattern := range patterns {
+		g := compileGlob(pattern)
+		for _, segment := range g {
+			if _, err := path.Match(segment, ""); err != nil {
+				return nil, fmt.Errorf("Error parsing pattern %q: %w", pattern, err)
+			}
+		}
+		gs = append(gs, g)
+	}
+
+	return gs, nil
+}
+
+func (gs globs) match(p string) bool {
+	for _, g := range gs {
+		if g.match(p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// gitignoreRule is a pattern of a .gitignore file in the directory dir.
+type gitignoreRule struct {
+	dir     string
+	pattern glob
+	negate  bool
+	dirOnly bool
+}
+
+// gitignore holds the rules of the .gitignore files read so far. Rules
+// of deeper directories come later and take precedence.
+type gitignore []*gitignoreRule
+
+// load reads the .gitignore file of dir, if there is one.
+func (g *gitignore) load(fsys fs.FS, dir string) error {
+	data, err := fs.ReadFile(fsys, path.Join(dir, ".gitignore"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := &gitignoreRule{dir: dir}
+		if rule.negate = strings.HasPrefix(line, "!"); rule.negate {
+			line = line[1:]
+		}
+		line = strings.TrimPrefix(line, `\`)
+
+		if rule.dirOnly = strings.HasSuffix(line, "/"); rule.dirOnly {
+			line = strings.TrimSuffix(line, "/")
+		}
+
+		// Patterns with a slash other than at the end are relative to
+		// the directory of the .gitignore file.
+		if strings.Contains(line, "/") && !strings.HasPrefix(line, "**/") {
+			line = "/" + strings.TrimPrefix(line, "/")
+		}
+
+		rule.pattern = compileGlob(line)
+		*g = append(*g, rule)
+	}
+
+	return scanner.Err()
+}
+
+// match reports whether the file or directory at p is ignored.
+func (g gitignore) match(p string, isDir bool) bool {
+	ignored := false
+	for _, rule := range g {
+		rel := p
+		if rule.dir != "." {
+			var ok bool
+			if rel, ok = strings.CutPrefix(p, rule.dir+"/"); !ok {
+				continue
+			}
+		}
+
+		if rule.dirOnly && !isDir {
+			continue
+		}
+
+		if rule.pattern.match(rel) {
+			ignored = !rule.negate
+		}
+	}
+
+	return ignored
+}
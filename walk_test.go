@@ -0,0 +1,200 @@
+package acrolinx
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var walkTestFS = fstest.MapFS{
+	".gitignore":               {Data: []byte("# Build output\n/build/\n*.tmp\n!keep.tmp\ndrafts/\n")},
+	"README.md":                {Data: []byte("# Readme\n")},
+	"notes.tmp":                {Data: []byte("Scratch")},
+	"keep.tmp":                 {Data: []byte("Kept")},
+	"build/out.md":             {Data: []byte("Generated")},
+	"docs/guide.md":            {Data: []byte("# Guide\n")},
+	"docs/api/index.html":      {Data: []byte("<p>API</p>")},
+	"docs/de/anleitung.md":     {Data: []byte("# Anleitung\n")},
+	"docs/drafts/wip.md":       {Data: []byte("Draft")},
+	"docs/.gitignore":          {Data: []byte("secret.md\n")},
+	"docs/secret.md":           {Data: []byte("Secret")},
+	"docs/build/page.md":       {Data: []byte("Not ignored, /build/ is anchored")},
+	"vendor/lib/README.md":     {Data: []byte("Vendored")},
+	".git/config":              {Data: []byte("[core]")},
+	"docs/fail.md":             {Data: []byte("fail")},
+	"docs/images/diagram.svg":  {Data: []byte("<svg/>")},
+	"docs/images/logo.png":     {Data: []byte("\x89PNG")},
+	"docs/api/reference.xhtml": {Data: []byte("<html/>")},
+}
+
+func TestFindFiles(t *testing.T) {
+	files, err := FindFiles(walkTestFS, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		".gitignore",
+		"README.md",
+		"docs/.gitignore",
+		"docs/api/index.html",
+		"docs/api/reference.xhtml",
+		"docs/build/page.md",
+		"docs/de/anleitung.md",
+		"docs/fail.md",
+		"docs/guide.md",
+		"docs/images/diagram.svg",
+		"docs/images/logo.png",
+		"keep.tmp",
+		"vendor/lib/README.md",
+	}, files)
+
+	files, err = FindFiles(walkTestFS, &WalkOptions{
+		Include: []string{"*.md", "docs/**/*.html"},
+		Exclude: []string{"vendor", "docs/de/**"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"README.md",
+		"docs/api/index.html",
+		"docs/build/page.md",
+		"docs/fail.md",
+		"docs/guide.md",
+	}, files)
+
+	files, err = FindFiles(walkTestFS, &WalkOptions{Include: []string{"/*.tmp", "docs/drafts/*"}, NoGitignore: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"docs/drafts/wip.md", "keep.tmp", "notes.tmp"}, files)
+
+	_, err = FindFiles(walkTestFS, &WalkOptions{Include: []string{"docs/[a"}})
+	assert.ErrorContains(t, err, `Error parsing pattern "docs/[a"`)
+}
+
+func TestMatchGlob(t *testing.T) {
+	for _, tc := range []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"*.md", "README.md", true},
+		{"*.md", "docs/a/b.md", true},
+		{"/*.md", "docs/a.md", false},
+		{"docs/*.md", "docs/a.md", true},
+		{"docs/*.md", "docs/a/b.md", false},
+		{"docs/**/*.md", "docs/a.md", true},
+		{"docs/**/*.md", "docs/a/b/c.md", true},
+		{"docs/**", "docs/a/b/c.md", true},
+		{"docs/**", "other/a.md", false},
+		{"**/api/*", "docs/api/x.html", true},
+	} {
+		assert.Equal(t, tc.want, compileGlob(tc.pattern).match(tc.path), "%s %s", tc.pattern, tc.path)
+	}
+}
+
+type walkChecker struct {
+	mu   sync.Mutex
+	opts map[string]*SubmitCheckOptions
+}
+
+// Check fails for content containing "fail" and reports the number of
+// words as the score otherwise.
+func (c *walkChecker) Check(ctx context.Context, opts *SubmitCheckOptions) (*CheckResult, error) {
+	c.mu.Lock()
+	if c.opts == nil {
+		c.opts = make(map[string]*SubmitCheckOptions)
+	}
+	c.opts[opts.Document.Reference] = opts
+	c.mu.Unlock()
+
+	if strings.Contains(opts.Content, "fail") {
+		return nil, errors.New("platform down")
+	}
+
+	return &CheckResult{Quality: &Quality{Score: len(strings.Fields(opts.Content))}}, nil
+}
+
+func TestCheckFS(t *testing.T) {
+	checker := &walkChecker{}
+	results, err := CheckFS(context.Background(), checker, walkTestFS, &WalkOptions{
+		Include:      []string{"docs/**/*.md", "docs/**/*.html"},
+		CheckOptions: &CheckOptions{GuidanceProfileID: "en", CheckType: "batch"},
+		Profiles: []*ProfileRule{
+			{Pattern: "docs/de/**", GuidanceProfileID: "de"},
+			{Pattern: "*.html", GuidanceProfileID: "web"},
+		},
+		ReferencePrefix: "https://example.com/repo/",
+		Concurrency:     2,
+	})
+	assert.NoError(t, err)
+
+	var paths []string
+	var failed []string
+	for r := range results {
+		paths = append(paths, r.Document.Path)
+		if r.Err != nil {
+			failed = append(failed, r.Err.Error())
+			continue
+		}
+		assert.Equal(t, string(walkTestFS[r.Document.Path].Data), r.Document.Content)
+		assert.NotNil(t, r.Document.Result)
+	}
+	sort.Strings(paths)
+
+	assert.Equal(t, []string{"docs/api/index.html", "docs/build/page.md", "docs/de/anleitung.md", "docs/fail.md", "docs/guide.md"}, paths)
+	assert.Equal(t, []string{"Error checking docs/fail.md: platform down"}, failed)
+
+	guide := checker.opts["https://example.com/repo/docs/guide.md"]
+	assert.Equal(t, "en", guide.CheckOptions.GuidanceProfileID)
+	assert.Equal(t, ContentFormatMarkdown, guide.CheckOptions.ContentFormat)
+	assert.Equal(t, "batch", guide.CheckOptions.CheckType)
+
+	assert.Equal(t, "de", checker.opts["https://example.com/repo/docs/de/anleitung.md"].CheckOptions.GuidanceProfileID)
+
+	api := checker.opts["https://example.com/repo/docs/api/index.html"]
+	assert.Equal(t, "web", api.CheckOptions.GuidanceProfileID)
+	assert.Equal(t, ContentFormatHTML, api.CheckOptions.ContentFormat)
+}
+
+func TestCheckFSFormats(t *testing.T) {
+	caps := &Capabilities{ContentFormats: []*ContentFormat{{ID: "TEXT"}, {ID: "MARKDOWN"}}}
+	formats, err := NewContentFormatResolver(caps)
+	assert.NoError(t, err)
+
+	checker := &walkChecker{}
+	results, err := CheckFS(context.Background(), checker, walkTestFS, &WalkOptions{
+		Include: []string{"README.md", "docs/api/index.html"},
+		Formats: formats,
+	})
+	assert.NoError(t, err)
+	for range results {
+	}
+
+	assert.Equal(t, ContentFormatMarkdown, checker.opts["README.md"].CheckOptions.ContentFormat)
+	assert.Equal(t, ContentFormatText, checker.opts["docs/api/index.html"].CheckOptions.ContentFormat)
+}
+
+func TestCheckFSCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	results, err := CheckFS(ctx, &walkChecker{}, walkTestFS, &WalkOptions{Concurrency: 1})
+	assert.NoError(t, err)
+
+	<-results
+	cancel()
+	for range results {
+	}
+}
+
+func TestCheckFSErrors(t *testing.T) {
+	_, err := CheckFS(context.Background(), &walkChecker{}, walkTestFS, &WalkOptions{
+		Profiles: []*ProfileRule{{Pattern: "[", GuidanceProfileID: "en"}},
+	})
+	assert.ErrorContains(t, err, "Error parsing pattern")
+
+	results, err := CheckFS(context.Background(), &walkChecker{}, fstest.MapFS{}, nil)
+	assert.NoError(t, err)
+	_, ok := <-results
+	assert.False(t, ok)
+}
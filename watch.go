@@ -0,0 +1,546 @@
+package acrolinx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultWatchInterval = 500 * time.Millisecond
+	defaultWatchDebounce = 300 * time.Millisecond
+)
+
+type WatchOptions struct {
+	// Walk selects the files to watch and how to check them.
+	Walk *WalkOptions
+	// Interval is the time between polls of the files. Defaults to
+	// 500ms.
+	Interval time.Duration
+	// Debounce is the time a file must stay unchanged before it is
+	// checked, so that a burst of saves triggers a single check.
+	// Defaults to 300ms.
+	Debounce time.Duration
+	// Align decides how far the partial check ranges of re-checks
+	// extend around changed lines.
+	Align Alignment
+	// CheckInitial checks all files when the watch starts. Otherwise
+	// files are only checked after they change.
+	CheckInitial bool
+}
+
+// fileStamp identifies a version of a file.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func (s fileStamp) equal(other fileStamp) bool {
+	return s.modTime.Equal(other.modTime) && s.size == other.size
+}
+
+// watchedFile is the state of a file being watched.
+type watchedFile struct {
+	read    bool
+	stamp   fileStamp
+	content []byte
+	// readErr is the last error reading the file, and errStamp the
+	// version it occurred with, so that it is only reported once.
+	readErr  string
+	errStamp fileStamp
+	// changedAt is the time the content last changed, or zero if the
+	// content has been checked.
+	changedAt time.Time
+	// checked is the last completed check of the file.
+	checked *CheckedDocument
+	// cancel cancels the check in flight, and gen counts the checks
+	// started, so that results of superseded checks are dropped.
+	cancel context.CancelFunc
+	gen    int
+}
+
+type watcher struct {
+	ctx     context.Context
+	checker Checker
+	fsys    fs.FS
+	opts    *WatchOptions
+
+	mu      sync.Mutex
+	files   map[string]*watchedFile
+	results chan *FileResult
+	sem     chan struct{}
+	wg      sync.WaitGroup
+}
+
+// Watch polls the files of fsys selected by opts and checks them after
+// they change. Files are re-checked with partial check ranges covering
+// the changed lines; the result then combines the issues found in the
+// ranges with the issues of the previous check in unchanged lines. A
+// check still running when its file changes again is cancelled.
+//
+// Changes are detected by modification time and size. Binary files of
+// text formats, like images, are skipped. Results are sent on the
+// returned channel, which is closed when ctx is done. An error is
+// returned if the files can't be listed.
+func Watch(ctx context.Context, checker Checker, fsys fs.FS, opts *WatchOptions) (<-chan *FileResult, error) {
+	o := WatchOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Walk == nil {
+		o.Walk = &WalkOptions{}
+	}
+	if o.Interval <= 0 {
+		o.Interval = defaultWatchInterval
+	}
+	if o.Debounce <= 0 {
+		o.Debounce = defaultWatchDebounce
+	}
+
+	concurrency := defaultWalkConcurrency
+	if o.Walk.Concurrency > 0 {
+		concurrency = o.Walk.Concurrency
+	}
+
+	w := &watcher{
+		ctx:     ctx,
+		checker: checker,
+		fsys:    fsys,
+		opts:    &o,
+		files:   make(map[string]*watchedFile),
+		results: make(chan *FileResult),
+		sem:     make(chan struct{}, concurrency),
+	}
+
+	paths, err := FindFiles(fsys, o.Walk)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	for _, p := range paths {
+		f := &watchedFile{}
+		if err := f.update(w.readChanged(p, nil)); err != nil {
+			return nil, fmt.Errorf("Error reading %s: %w", p, err)
+		}
+		f.changedAt = time.Time{}
+		if o.CheckInitial {
+			f.changedAt = start.Add(-o.Debounce)
+		}
+		w.files[p] = f
+	}
+
+	go w.run()
+
+	return w.results, nil
+}
+
+func (w *watcher) run() {
+	ticker := time.NewTicker(w.opts.Interval)
+	defer ticker.Stop()
+
+	if w.opts.CheckInitial {
+		w.dispatchDue(time.Now())
+	}
+
+	for {
+		select {
+		case now := <-ticker.C:
+			w.poll(now)
+		case <-w.ctx.Done():
+			w.wg.Wait()
+			close(w.results)
+			return
+		}
+	}
+}
+
+// fileRead is the outcome of reading a watched file.
+type fileRead struct {
+	stamp   fileStamp
+	content []byte
+	err     error
+}
+
+// readChanged reads the file at p unless its version is known. It
+// returns nil if the file is unchanged.
+func (w *watcher) readChanged(p string, known *fileStamp) *fileRead {
+	info, err := fs.Stat(w.fsys, p)
+	if err != nil {
+		return &fileRead{err: err}
+	}
+
+	stamp := fileStamp{info.ModTime(), info.Size()}
+	if known != nil && known.equal(stamp) {
+		return nil
+	}
+
+	content, err := fs.ReadFile(w.fsys, p)
+	return &fileRead{stamp: stamp, content: content, err: err}
+}
+
+// knownStamp returns the version of the file last read or failed to
+// read, or nil if there is none.
+func (f *watchedFile) knownStamp() *fileStamp {
+	switch {
+	case f.readErr != "":
+		return &f.errStamp
+	case f.read:
+		return &f.stamp
+	}
+
+	return nil
+}
+
+// update applies r to f, and sets f.changedAt if the content changed.
+// It returns the read error unless it was already returned for the
+// same version of the file.
+func (f *watchedFile) update(r *fileRead) error {
+	if r == nil {
+		return nil
+	}
+
+	if r.err != nil {
+		repeated := r.err.Error() == f.readErr && r.stamp.equal(f.errStamp)
+		f.readErr, f.errStamp = r.err.Error(), r.stamp
+		if repeated {
+			return nil
+		}
+		return r.err
+	}
+
+	f.readErr, f.errStamp = "", fileStamp{}
+	f.stamp = r.stamp
+	if !f.read || !bytes.Equal(r.content, f.content) {
+		f.read = true
+		f.content = r.content
+		f.changedAt = time.Now()
+	}
+
+	return nil
+}
+
+// poll looks for added, changed and removed files and checks the files
+// that have settled. Files are read without holding w.mu, so that
+// completing checks aren't held up.
+func (w *watcher) poll(now time.Time) {
+	paths, err := FindFiles(w.fsys, w.opts.Walk)
+	if err != nil {
+		w.send(&FileResult{Document: &CheckedDocument{}, Err: err})
+		return
+	}
+
+	w.mu.Lock()
+	seen := make(map[string]bool, len(paths))
+	known := make(map[string]*fileStamp, len(paths))
+	for _, p := range paths {
+		seen[p] = true
+		if f, ok := w.files[p]; ok {
+			known[p] = f.knownStamp()
+		}
+	}
+
+	for p, f := range w.files {
+		if !seen[p] {
+			if f.cancel != nil {
+				f.cancel()
+			}
+			delete(w.files, p)
+		}
+	}
+	w.mu.Unlock()
+
+	reads := make(map[string]*fileRead, len(paths))
+	for _, p := range paths {
+		reads[p] = w.readChanged(p, known[p])
+	}
+
+	var errs []*FileResult
+	w.mu.Lock()
+	for _, p := range paths {
+		f, ok := w.files[p]
+		if !ok {
+			f = &watchedFile{}
+			w.files[p] = f
+		}
+
+		if err := f.update(reads[p]); err != nil {
+			errs = append(errs, &FileResult{
+				Document: &CheckedDocument{Path: p},
+				Err:      fmt.Errorf("Error reading %s: %w", p, err),
+			})
+		}
+	}
+	w.mu.Unlock()
+
+	for _, r := range errs {
+		w.send(r)
+	}
+
+	w.dispatchDue(now)
+}
+
+// dispatchDue starts checks for the files that haven't changed for the
+// debounce time.
+func (w *watcher) dispatchDue(now time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var due []string
+	for p, f := range w.files {
+		if !f.changedAt.IsZero() && now.Sub(f.changedAt) >= w.opts.Debounce {
+			due = append(due, p)
+		}
+	}
+	sort.Strings(due)
+
+	for _, p := range due {
+		w.dispatch(p, w.files[p])
+	}
+}
+
+// dispatch starts checking the current content of f, cancelling the
+// check in flight. Content reverted to the last checked version isn't
+// checked again. It must be called with w.mu held.
+func (w *watcher) dispatch(p string, f *watchedFile) {
+	if f.cancel != nil {
+		f.cancel()
+		f.cancel = nil
+	}
+
+	f.changedAt = time.Time{}
+	if f.checked != nil && f.checked.Content == string(f.content) {
+		f.gen++
+		return
+	}
+
+	ctx, cancel := context.WithCancel(w.ctx)
+	f.cancel = cancel
+	f.gen++
+	gen, content, previous := f.gen, f.content, f.checked
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		defer cancel()
+
+		select {
+		case w.sem <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+		result := w.check(ctx, p, content, previous)
+		<-w.sem
+
+		w.mu.Lock()
+		current := w.files[p] == f && f.gen == gen && ctx.Err() == nil
+		if current {
+			f.cancel = nil
+			if result != nil && result.Err == nil {
+				f.checked = result.Document
+			}
+		}
+		w.mu.Unlock()
+
+		if current && result != nil {
+			w.send(result)
+		}
+	}()
+}
+
+func (w *watcher) send(result *FileResult) {
+	select {
+	case w.results <- result:
+	case <-w.ctx.Done():
+	}
+}
+
+// check checks content, limited to the lines changed since the
+// previous check if there is one. It returns nil if the file is
+// skipped.
+func (w *watcher) check(ctx context.Context, p string, content []byte, previous *CheckedDocument) *FileResult {
+	doc := &CheckedDocument{Path: p, Content: string(content)}
+	opts, err := w.opts.Walk.submitOptions(p, content)
+	if errors.Is(err, ErrBinaryContent) {
+		return nil
+	}
+	if opts.ContentEncoding == ContentEncodingBase64 {
+		doc.Content = ""
+	}
+
+	var ranges []*PartialCheckRange
+	if previous != nil && previous.Result != nil && opts.ContentEncoding != ContentEncodingBase64 {
+		ranges = NewFileDiff(previous.Content, doc.Content).PartialCheckRanges(doc.Content, w.opts.Align)
+		opts.CheckOptions.PartialCheckRanges = ranges
+	}
+
+	result, err := w.checker.Check(ctx, opts)
+	if err != nil {
+		return &FileResult{Document: doc, Err: fmt.Errorf("Error checking %s: %w", p, err)}
+	}
+
+	if len(ranges) > 0 {
+		result = mergePartialResult(previous, doc.Content, result, ranges)
+	}
+	doc.Result = result
+
+	return &FileResult{Document: doc}
+}
+
+// mergePartialResult combines the issues a partial check found in
+// ranges with the issues of the previous check in unchanged lines,
+// moved to their offsets in content. The quality of the partial check
+// only covers the ranges, so the merged result has none; its issue
+// counts are recomputed, and word and sentence counts are kept from the
+// previous check.
+func mergePartialResult(previous *CheckedDocument, content string, partial *CheckResult, ranges []*PartialCheckRange) *CheckResult {
+	merged := *partial
+	merged.Issues = nil
+	merged.Quality = nil
+	merged.Goals = mergeGoals(previous.Result.Goals, partial.Goals)
+
+	for _, issue := range partial.Issues {
+		if !hasMatches(issue) || issueInRanges(issue, ranges) {
+			merged.Issues = append(merged.Issues, issue)
+		}
+	}
+
+	remap := lineRemapper(previous.Content, content)
+	for _, issue := range previous.Result.Issues {
+		if !hasMatches(issue) {
+			continue
+		}
+
+		if moved, ok := remapIssue(issue, remap); ok && !issueInRanges(moved, ranges) {
+			merged.Issues = append(merged.Issues, moved)
+		}
+	}
+
+	counts := &Counts{Issues: len(merged.Issues)}
+	if previous.Result.Counts != nil {
+		counts.Sentences = previous.Result.Counts.Sentences
+		counts.Words = previous.Result.Counts.Words
+	}
+	doc := &CheckedDocument{Result: &merged}
+	for _, issue := range merged.Issues {
+		if doc.scoring(issue) != "" {
+			counts.ScoredIssues++
+		}
+	}
+	merged.Counts = counts
+
+	return &merged
+}
+
+// mergeGoals returns the goals of previous, with goals of the same ID
+// replaced by those of partial, followed by the goals only in partial.
+func mergeGoals(previous []*Goal, partial []*Goal) []*Goal {
+	byID := make(map[string]*Goal, len(partial))
+	for _, g := range partial {
+		byID[g.ID] = g
+	}
+
+	var goals []*Goal
+	for _, g := range previous {
+		if p, ok := byID[g.ID]; ok {
+			g = p
+			delete(byID, g.ID)
+		}
+		goals = append(goals, g)
+	}
+	for _, g := range partial {
+		if _, ok := byID[g.ID]; ok {
+			goals = append(goals, g)
+		}
+	}
+
+	return goals
+}
+
+func hasMatches(issue *Issue) bool {
+	return issue.PositionalInformation != nil && len(issue.PositionalInformation.Matches) > 0
+}
+
+// lineRemapper returns a function moving UTF-16 offsets in unchanged
+// lines of original to their offsets in modified.
+func lineRemapper(original string, modified string) func(int) (int, bool) {
+	oldStarts, newStarts := utf16LineStarts(original), utf16LineStarts(modified)
+
+	lines := make(map[int]int)
+	for _, op := range diffLines(splitLines(original), splitLines(modified)) {
+		if op.kind == ' ' {
+			lines[op.a] = op.b
+		}
+	}
+
+	return func(offset int) (int, bool) {
+		line := sort.SearchInts(oldStarts, offset+1) - 1
+		newLine, ok := lines[line]
+		if !ok {
+			return 0, false
+		}
+
+		return newStarts[newLine] + offset - oldStarts[line], true
+	}
+}
+
+// utf16LineStarts returns the UTF-16 offsets of the beginnings of the
+// lines of content.
+func utf16LineStarts(content string) []int {
+	x := NewPositionIndex(content)
+	starts := lineByteStarts(content)
+	offsets := make([]int, len(starts)-1)
+	for i := range offsets {
+		offsets[i] = x.FromByte(starts[i]).UTF16
+	}
+
+	return offsets
+}
+
+// remapIssue returns a copy of issue with the offsets of its matches
+// moved by remap. It returns false if a match can't be moved.
+func remapIssue(issue *Issue, remap func(int) (int, bool)) (*Issue, bool) {
+	moved := *issue
+	moved.Range = nil
+
+	if info := issue.PositionalInformation; info != nil {
+		moved.PositionalInformation = &PositionalInformation{Hashes: info.Hashes}
+		for _, m := range info.Matches {
+			begin, ok := remap(m.OriginalBegin)
+			if !ok {
+				return nil, false
+			}
+
+			end := begin
+			if m.OriginalEnd > m.OriginalBegin {
+				last, ok := remap(m.OriginalEnd - 1)
+				if !ok {
+					return nil, false
+				}
+				end = last + 1
+			}
+
+			match := *m
+			match.OriginalBegin, match.OriginalEnd = begin, end
+			moved.PositionalInformation.Matches = append(moved.PositionalInformation.Matches, &match)
+		}
+	}
+
+	if issue.SubIssues != nil {
+		moved.SubIssues = make([]*Issue, len(issue.SubIssues))
+		for i, sub := range issue.SubIssues {
+			s, ok := remapIssue(sub, remap)
+			if !ok {
+				return nil, false
+			}
+			moved.SubIssues[i] = s
+		}
+	}
+
+	return &moved, true
+}
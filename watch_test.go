@@ -0,0 +1,381 @@
+package acrolinx
+
+import (
+	"context"
+	"io/fs"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// watchFS is a MapFS that can be changed while it is watched.
+type watchFS struct {
+	mu         sync.Mutex
+	files      fstest.MapFS
+	unreadable map[string]bool
+	now        time.Time
+}
+
+func newWatchFS(files map[string]string) *watchFS {
+	w := &watchFS{files: fstest.MapFS{}, now: time.Unix(0, 0)}
+	for p, content := range files {
+		w.set(p, content)
+	}
+	return w
+}
+
+func (w *watchFS) Open(name string) (fs.File, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.unreadable[name] {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrPermission}
+	}
+	return w.files.Open(name)
+}
+
+func (w *watchFS) setReadable(p string, readable bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.unreadable == nil {
+		w.unreadable = make(map[string]bool)
+	}
+	w.unreadable[p] = !readable
+}
+
+func (w *watchFS) set(p string, content string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.now = w.now.Add(time.Second)
+	w.files[p] = &fstest.MapFile{Data: []byte(content), ModTime: w.now}
+}
+
+func (w *watchFS) remove(p string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.files, p)
+}
+
+type watchChecker struct {
+	mu      sync.Mutex
+	opts    []*SubmitCheckOptions
+	started chan string
+}
+
+// Check reports every "bad" in the partial check ranges, or the whole
+// content without ranges. Checks of content containing "slow" block
+// until they are cancelled.
+func (c *watchChecker) Check(ctx context.Context, opts *SubmitCheckOptions) (*CheckResult, error) {
+	c.mu.Lock()
+	c.opts = append(c.opts, opts)
+	c.mu.Unlock()
+
+	if c.started != nil {
+		c.started <- opts.Content
+	}
+
+	if strings.Contains(opts.Content, "slow") {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	ranges := opts.CheckOptions.PartialCheckRanges
+	if ranges == nil {
+		ranges = []*PartialCheckRange{{Begin: 0, End: len(opts.Content)}}
+	}
+
+	result := &CheckResult{Quality: &Quality{Score: 100}}
+	for _, r := range ranges {
+		part := opts.Content[r.Begin:r.End]
+		for i := strings.Index(part, "bad"); i >= 0; {
+			begin := r.Begin + i
+			result.Issues = append(result.Issues, &Issue{
+				InternalName: "bad",
+				PositionalInformation: &PositionalInformation{
+					Matches: []*Match{{OriginalPart: "bad", OriginalBegin: begin, OriginalEnd: begin + 3}},
+				},
+			})
+			next := strings.Index(part[i+3:], "bad")
+			if next < 0 {
+				break
+			}
+			i += 3 + next
+		}
+	}
+
+	return result, nil
+}
+
+func (c *watchChecker) calls() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.opts)
+}
+
+func nextWatchResult(t *testing.T, results <-chan *FileResult) *FileResult {
+	t.Helper()
+	select {
+	case r := <-results:
+		assert.NotNil(t, r)
+		return r
+	case <-time.After(5 * time.Second):
+		t.Fatal("no watch result")
+		return nil
+	}
+}
+
+func issueBegins(result *CheckResult) []int {
+	var begins []int
+	for _, issue := range result.Issues {
+		begins = append(begins, issue.PositionalInformation.Matches[0].OriginalBegin)
+	}
+	return begins
+}
+
+func TestWatch(t *testing.T) {
+	fsys := newWatchFS(map[string]string{
+		"a.md": "Good.\n\nThis is bad.\n",
+		"b.md": "Fine.\n",
+	})
+	checker := &watchChecker{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	results, err := Watch(ctx, checker, fsys, &WatchOptions{
+		Walk:         &WalkOptions{CheckOptions: &CheckOptions{GuidanceProfileID: "en"}},
+		Interval:     5 * time.Millisecond,
+		Debounce:     20 * time.Millisecond,
+		CheckInitial: true,
+	})
+	assert.NoError(t, err)
+
+	initial := map[string]*FileResult{}
+	for i := 0; i < 2; i++ {
+		r := nextWatchResult(t, results)
+		initial[r.Document.Path] = r
+	}
+	assert.NoError(t, initial["a.md"].Err)
+	assert.Equal(t, []int{15}, issueBegins(initial["a.md"].Document.Result))
+	assert.Empty(t, initial["b.md"].Document.Result.Issues)
+
+	// Only the changed sentence is checked; the issue in the unchanged
+	// line is carried over to its new offset.
+	fsys.set("a.md", "Good, not bad.\n\nThis is bad.\n")
+	r := nextWatchResult(t, results)
+	assert.Equal(t, "a.md", r.Document.Path)
+	assert.Equal(t, "Good, not bad.\n\nThis is bad.\n", r.Document.Content)
+	assert.Equal(t, []int{10, 24}, issueBegins(r.Document.Result))
+	assert.Nil(t, r.Document.Result.Quality)
+	assert.Equal(t, &Counts{Issues: 2}, r.Document.Result.Counts)
+	assert.Equal(t, []*PartialCheckRange{{Begin: 0, End: 14}}, checker.opts[2].CheckOptions.PartialCheckRanges)
+	assert.Equal(t, "en", checker.opts[2].CheckOptions.GuidanceProfileID)
+
+	// Fixing an issue removes it.
+	fsys.set("a.md", "Good, not bad.\n\nThis is good.\n")
+	r = nextWatchResult(t, results)
+	assert.Equal(t, []int{10}, issueBegins(r.Document.Result))
+
+	// New files are checked in full.
+	fsys.set("c.md", "Also bad.\n")
+	r = nextWatchResult(t, results)
+	assert.Equal(t, "c.md", r.Document.Path)
+	assert.Equal(t, []int{5}, issueBegins(r.Document.Result))
+	assert.Nil(t, checker.opts[4].CheckOptions.PartialCheckRanges)
+
+	fsys.remove("c.md")
+	cancel()
+	for range results {
+	}
+	assert.Equal(t, 5, checker.calls())
+}
+
+func TestCheckBinaryFiles(t *testing.T) {
+	files := map[string]string{
+		"doc.md":      "Text.\n",
+		"slides.pptx": "PK\x03\x04\x00\xff",
+		"logo.png":    "\x89PNG\r\n\x1a\n",
+	}
+
+	assertChecked := func(checker *walkChecker, paths []string) {
+		t.Helper()
+		sort.Strings(paths)
+		assert.Equal(t, []string{"doc.md", "slides.pptx"}, paths)
+		assert.Equal(t, "Text.\n", checker.opts["doc.md"].Content)
+
+		slides := checker.opts["slides.pptx"]
+		assert.Equal(t, ContentFormatMSOffice, slides.CheckOptions.ContentFormat)
+		assert.Equal(t, ContentEncodingBase64, slides.ContentEncoding)
+		data, err := slides.BinaryContent()
+		assert.NoError(t, err)
+		assert.Equal(t, files["slides.pptx"], string(data))
+	}
+
+	checker := &walkChecker{}
+	results, err := CheckFS(context.Background(), checker, newWatchFS(files), nil)
+	assert.NoError(t, err)
+	var paths []string
+	for r := range results {
+		assert.NoError(t, r.Err)
+		paths = append(paths, r.Document.Path)
+		if r.Document.Path == "slides.pptx" {
+			assert.Empty(t, r.Document.Content)
+		}
+	}
+	assertChecked(checker, paths)
+
+	checker = &walkChecker{}
+	ctx, cancel := context.WithCancel(context.Background())
+	watched, err := Watch(ctx, checker, newWatchFS(files), &WatchOptions{Interval: 5 * time.Millisecond, CheckInitial: true})
+	assert.NoError(t, err)
+	paths = nil
+	for i := 0; i < 2; i++ {
+		paths = append(paths, nextWatchResult(t, watched).Document.Path)
+	}
+	cancel()
+	for r := range watched {
+		paths = append(paths, r.Document.Path)
+	}
+	assertChecked(checker, paths)
+}
+
+func TestWatchDebounce(t *testing.T) {
+	fsys := newWatchFS(map[string]string{"a.md": "One.\n"})
+	checker := &watchChecker{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	results, err := Watch(ctx, checker, fsys, &WatchOptions{
+		Interval: 5 * time.Millisecond,
+		Debounce: 200 * time.Millisecond,
+	})
+	assert.NoError(t, err)
+
+	for _, content := range []string{"One. Two.\n", "One. Two. Three.\n", "One. Two. Three. Four.\n"} {
+		fsys.set("a.md", content)
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	r := nextWatchResult(t, results)
+	assert.Equal(t, "One. Two. Three. Four.\n", r.Document.Content)
+	assert.Equal(t, 1, checker.calls())
+
+	// Reverting to the checked content doesn't trigger a check.
+	fsys.set("a.md", "Changed.\n")
+	time.Sleep(20 * time.Millisecond)
+	fsys.set("a.md", "One. Two. Three. Four.\n")
+	time.Sleep(400 * time.Millisecond)
+	assert.Equal(t, 1, checker.calls())
+}
+
+func TestWatchCancelsSupersededChecks(t *testing.T) {
+	fsys := newWatchFS(map[string]string{"a.md": "Start.\n"})
+	checker := &watchChecker{started: make(chan string, 10)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	results, err := Watch(ctx, checker, fsys, &WatchOptions{
+		Interval: 5 * time.Millisecond,
+		Debounce: 10 * time.Millisecond,
+	})
+	assert.NoError(t, err)
+
+	fsys.set("a.md", "This is slow.\n")
+	assert.Equal(t, "This is slow.\n", <-checker.started)
+
+	fsys.set("a.md", "This is bad.\n")
+	r := nextWatchResult(t, results)
+	assert.NoError(t, r.Err)
+	assert.Equal(t, "This is bad.\n", r.Document.Content)
+	assert.Equal(t, []int{8}, issueBegins(r.Document.Result))
+}
+
+func TestWatchReadErrors(t *testing.T) {
+	fsys := newWatchFS(map[string]string{"a.md": "Fine.\n"})
+	checker := &watchChecker{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	results, err := Watch(ctx, checker, fsys, &WatchOptions{
+		Interval: 5 * time.Millisecond,
+		Debounce: 10 * time.Millisecond,
+	})
+	assert.NoError(t, err)
+
+	// An unreadable file is reported once, not on every poll.
+	fsys.setReadable("a.md", false)
+	r := nextWatchResult(t, results)
+	assert.ErrorIs(t, r.Err, fs.ErrPermission)
+	assert.Equal(t, "a.md", r.Document.Path)
+
+	time.Sleep(50 * time.Millisecond)
+	fsys.setReadable("a.md", true)
+	fsys.set("a.md", "Now bad.\n")
+	r = nextWatchResult(t, results)
+	assert.NoError(t, r.Err)
+	assert.Equal(t, "Now bad.\n", r.Document.Content)
+
+	// It is reported again after it became readable.
+	fsys.setReadable("a.md", false)
+	r = nextWatchResult(t, results)
+	assert.ErrorIs(t, r.Err, fs.ErrPermission)
+}
+
+func TestWatchErrors(t *testing.T) {
+	_, err := Watch(context.Background(), &watchChecker{}, newWatchFS(nil), &WatchOptions{
+		Walk: &WalkOptions{Include: []string{"["}},
+	})
+	assert.ErrorContains(t, err, "Error parsing pattern")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	results, err := Watch(ctx, &watchChecker{}, newWatchFS(nil), nil)
+	assert.NoError(t, err)
+	cancel()
+	_, ok := <-results
+	assert.False(t, ok)
+}
+
+func TestNewFileDiff(t *testing.T) {
+	d := NewFileDiff("a\nb\nc\nd\n", "a\nB\nc\ne\nf\n")
+	assert.Equal(t, []int{2, 4, 5}, d.Added)
+	assert.Equal(t, []int{2, 4}, d.Deleted)
+
+	d = NewFileDiff("a\nb\nc\n", "a\n")
+	assert.Empty(t, d.Added)
+	assert.Equal(t, []int{2}, d.Deleted)
+}
+
+func TestMergePartialResult(t *testing.T) {
+	issue := func(goal string, begin int) *Issue {
+		return &Issue{
+			GoalID: goal,
+			PositionalInformation: &PositionalInformation{
+				Matches: []*Match{{OriginalPart: "bad", OriginalBegin: begin, OriginalEnd: begin + 3}},
+			},
+		}
+	}
+
+	previous := &CheckedDocument{
+		Content: "One bad.\nTwo.\n",
+		Result: &CheckResult{
+			Quality: &Quality{Score: 50},
+			Counts:  &Counts{Sentences: 2, Words: 3, Issues: 1, ScoredIssues: 1},
+			Goals:   []*Goal{{ID: "clarity", Scoring: "required"}, {ID: "tone", Scoring: "recommended"}},
+			Issues:  []*Issue{issue("clarity", 4)},
+		},
+	}
+	partial := &CheckResult{
+		Quality: &Quality{Score: 90},
+		Counts:  &Counts{Sentences: 1, Words: 2, Issues: 1, ScoredIssues: 0},
+		Goals:   []*Goal{{ID: "tone", Scoring: "suggestion"}, {ID: "spelling"}},
+		Issues:  []*Issue{issue("spelling", 13)},
+	}
+
+	merged := mergePartialResult(previous, "One bad.\nTwo bad.\n", partial, []*PartialCheckRange{{Begin: 9, End: 17}})
+	assert.Nil(t, merged.Quality)
+	assert.Equal(t, &Counts{Sentences: 2, Words: 3, Issues: 2, ScoredIssues: 1}, merged.Counts)
+	assert.Equal(t, []*Goal{{ID: "clarity", Scoring: "required"}, {ID: "tone", Scoring: "suggestion"}, {ID: "spelling"}}, merged.Goals)
+	assert.Equal(t, []int{13, 4}, issueBegins(merged))
+}